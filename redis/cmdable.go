@@ -0,0 +1,92 @@
+package gedis
+
+import (
+	"time"
+)
+
+// 与部署方式无关的按key操作的命令集合，由Gedis、ShardedGedis、Pipeline及Tx实现，
+// 业务代码依赖该接口即可在单节点、分片及哨兵部署之间切换，也便于在单元测试中mock。
+// 在Pipeline/Tx中调用时命令只入队，返回值为零值，结果需要通过Exec获取
+type Cmdable interface {
+	// key
+	Del(keys... string) (int, error)
+	Exists(keys... string) (int64, error)
+	Type(key string) (string, error)
+	Expire(key string, ttl time.Duration, cond ExpireCondition) (bool, error)
+	PExpire(key string, ttl time.Duration, cond ExpireCondition) (bool, error)
+	TTL(key string) (KeyTTL, error)
+	Persist(key string) (bool, error)
+
+	// string
+	Get(key string) *Reply
+	Set(key string, value interface{}) (string, error)
+	SetWithOptions(key string, value interface{}, opt *SetOptions) (string, error)
+	GetEx(key string, opt *GetExOptions) (string, error)
+	GetDel(key string) (string, error)
+	GetSet(key string, value interface{}) (string, error)
+	Incr(key string) (int64, error)
+	IncrBy(key string, increment int64) (int64, error)
+	IncrByFloat(key string, increment float64) (float64, error)
+	Decr(key string) (int64, error)
+	DecrBy(key string, decrement int64) (int64, error)
+	MGet(keys... string) ([][]byte, error)
+	MSet(pairs map[string]interface{}) (string, error)
+	MSetNX(pairs map[string]interface{}) (bool, error)
+	Append(key string, value interface{}) (int64, error)
+	GetRange(key string, start, end int64) (string, error)
+	SetRange(key string, offset int64, value interface{}) (int64, error)
+	StrLen(key string) (int64, error)
+
+	// hash
+	HSet(key string, pairs map[string]interface{}) (int64, error)
+	HGet(key, field string) (string, error)
+	HMGet(key string, fields... string) ([][]byte, error)
+	HGetAll(key string) (map[string]string, error)
+	HDel(key string, fields... string) (int64, error)
+	HExists(key, field string) (bool, error)
+	HIncrBy(key, field string, increment int64) (int64, error)
+	HIncrByFloat(key, field string, increment float64) (float64, error)
+	HKeys(key string) ([]string, error)
+	HVals(key string) ([]string, error)
+	HLen(key string) (int64, error)
+	HSetNX(key, field string, value interface{}) (bool, error)
+
+	// list
+	LPush(key string, values... interface{}) (int64, error)
+	RPush(key string, values... interface{}) (int64, error)
+	LPop(key string) (string, error)
+	RPop(key string) (string, error)
+	LLen(key string) (int64, error)
+	LRange(key string, start, stop int64) ([]string, error)
+	LIndex(key string, index int64) (string, error)
+	LSet(key string, index int64, value interface{}) (string, error)
+	LRem(key string, count int64, value interface{}) (int64, error)
+	LTrim(key string, start, stop int64) (string, error)
+
+	// set
+	SAdd(key string, members... interface{}) (int64, error)
+	SRem(key string, members... interface{}) (int64, error)
+	SMembers(key string) ([]string, error)
+	SIsMember(key string, member interface{}) (bool, error)
+	SCard(key string) (int64, error)
+	SPop(key string) (string, error)
+	SRandMember(key string) (string, error)
+
+	// sorted set
+	ZAdd(key string, opt *ZAddOptions, members... Z) (int64, error)
+	ZRem(key string, members... interface{}) (int64, error)
+	ZScore(key string, member interface{}) (float64, error)
+	ZIncrBy(key string, increment float64, member interface{}) (float64, error)
+	ZCard(key string) (int64, error)
+	ZCount(key string, min, max ScoreBound) (int64, error)
+	ZRank(key string, member interface{}) (int64, error)
+	ZRevRank(key string, member interface{}) (int64, error)
+	ZRange(z *ZRangeArgs) ([]string, error)
+}
+
+var (
+	_ Cmdable = (*Gedis)(nil)
+	_ Cmdable = (*ShardedGedis)(nil)
+	_ Cmdable = (*Pipeline)(nil)
+	_ Cmdable = (*Tx)(nil)
+)
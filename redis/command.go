@@ -0,0 +1,671 @@
+package gedis
+
+import (
+	"bytes"
+	"errors"
+	"redis/resp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 命令的元数据，来自COMMAND INFO/COMMAND DOCS或内置的默认表
+
+// 一个命令(或子命令)的元数据
+type CommandInfo struct {
+	// 小写的命令名，子命令为 container|subcommand 的形式，如 config|get
+	Name          string
+	// 参数个数(包括命令名)，负数表示至少-Arity个
+	Arity         int
+	// 如readonly、write、denyoom、blocking、pubsub、movablekeys等
+	Flags         []string
+	// 旧的key位置描述，LastKey为负数时从末尾开始计算
+	FirstKey      int
+	LastKey       int
+	Step          int
+	// 如@read、@write、@string等
+	ACLCategories []string
+	KeySpecs      []CommandKeySpec
+	// key为子命令的小写名称(不含container)
+	Subcommands   map[string]*CommandInfo
+
+	// 以下来自COMMAND DOCS
+	Summary       string
+	Since         string
+	Group         string
+}
+
+// key spec(Redis 7.0+)，描述如何从参数中找出key
+type CommandKeySpec struct {
+	// 如RO、RW、OW、RM、access、update、insert、delete等
+	Flags            []string
+
+	// begin_search: BeginKeyword为空时从下标BeginIndex开始，
+	// 否则从KeywordStartFrom开始(负数表示从末尾向前)查找关键字，从关键字的下一个参数开始
+	BeginIndex       int
+	BeginKeyword     string
+	KeywordStartFrom int
+
+	// find_keys: KeyNum为false时为range类型，LastKey相对于开始位置，负数表示从末尾开始计算，
+	// Limit大于1时表示只取剩余参数的1/Limit；KeyNum为true时key的数量在KeyNumIdx位置，第一个key在FirstKey位置
+	KeyNum           bool
+	LastKey          int
+	KeyStep          int
+	Limit            int
+	KeyNumIdx        int
+	FirstKey         int
+
+	// begin_search或find_keys为unknown类型时，只能通过COMMAND GETKEYS获取key
+	Unknown          bool
+}
+
+func (c *CommandInfo)HasFlag(flag string) bool {
+	for _, f := range c.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CommandInfo)ReadOnly() bool {
+	return c.HasFlag("readonly")
+}
+
+func (c *CommandInfo)Write() bool {
+	return c.HasFlag("write")
+}
+
+func (c *CommandInfo)Blocking() bool {
+	return c.HasFlag("blocking")
+}
+
+func (c *CommandInfo)PubSub() bool {
+	return c.HasFlag("pubsub")
+}
+
+// key的位置取决于参数(如EVAL、ZUNION)
+func (c *CommandInfo)MovableKeys() bool {
+	return c.HasFlag("movablekeys")
+}
+
+// 命令是否可以在失败(如连接断开)后安全地重新发送：只读且不阻塞
+func (c *CommandInfo)Retryable() bool {
+	return c.ReadOnly() && !c.Blocking() && !c.PubSub()
+}
+
+// 从argv(包括命令名)中找出key，无法确定时(key spec为unknown)返回false，此时需要使用COMMAND GETKEYS
+func (c *CommandInfo)Keys(argv []string) ([]string, bool) {
+	if len(c.KeySpecs) == 0 {
+		return c.legacyKeys(argv), true
+	}
+	keys := make([]string, 0, 2)
+	for i := range c.KeySpecs {
+		k, ok := c.KeySpecs[i].keys(argv)
+		if !ok {
+			return nil, false
+		}
+		keys = append(keys, k...)
+	}
+	return keys, true
+}
+
+func (c *CommandInfo)legacyKeys(argv []string) []string {
+	if c.FirstKey <= 0 {
+		return nil
+	}
+	last := c.LastKey
+	if last < 0 {
+		last = len(argv) + last
+	}
+	step := c.Step
+	if step <= 0 {
+		step = 1
+	}
+	keys := make([]string, 0, 2)
+	for i := c.FirstKey; i <= last && i < len(argv); i += step {
+		keys = append(keys, argv[i])
+	}
+	return keys
+}
+
+func (s *CommandKeySpec)keys(argv []string) ([]string, bool) {
+	if s.Unknown {
+		return nil, false
+	}
+	start := s.BeginIndex
+	if s.BeginKeyword != "" {
+		start = -1
+		if s.KeywordStartFrom >= 0 {
+			for i := s.KeywordStartFrom; i < len(argv); i++ {
+				if strings.EqualFold(argv[i], s.BeginKeyword) {
+					start = i + 1
+					break
+				}
+			}
+		} else {
+			for i := len(argv) + s.KeywordStartFrom; i > 0; i-- {
+				if strings.EqualFold(argv[i], s.BeginKeyword) {
+					start = i + 1
+					break
+				}
+			}
+		}
+		if start < 0 {
+			// 没有找到关键字，说明该可选部分不存在
+			return nil, true
+		}
+	}
+	if start <= 0 || start >= len(argv) {
+		return nil, true
+	}
+	step := s.KeyStep
+	if step <= 0 {
+		step = 1
+	}
+	var first, last int
+	if s.KeyNum {
+		if start + s.KeyNumIdx >= len(argv) {
+			return nil, true
+		}
+		n, err := strconv.Atoi(argv[start + s.KeyNumIdx])
+		if err != nil {
+			return nil, false
+		}
+		first = start + s.FirstKey
+		last = first + (n - 1) * step
+	} else {
+		first = start
+		if s.LastKey >= 0 {
+			last = start + s.LastKey
+		} else {
+			last = len(argv) + s.LastKey
+			if s.Limit > 1 {
+				last = start + (last - start + 1) / s.Limit - 1
+			}
+		}
+	}
+	keys := make([]string, 0, 2)
+	for i := first; i <= last && i < len(argv); i += step {
+		keys = append(keys, argv[i])
+	}
+	return keys, true
+}
+
+// 返回命令的元数据，names为空时返回所有命令
+func (g *Gedis)CommandInfo(names... string) ([]*CommandInfo, error) {
+	var r *Reply
+	if len(names) == 0 {
+		r = g.Cmd("COMMAND")
+	} else {
+		r = g.Cmd("COMMAND", "INFO", names)
+	}
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	infos := make([]*CommandInfo, 0, len(r.Children))
+	for _, c := range r.Children {
+		// 不存在的命令返回nil
+		if c.Type == NilReply {
+			continue
+		}
+		info, err := parseCommandInfo(c)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// [name, arity, flags, first, last, step, acl categories, tips, key specs, subcommands]，
+// ACL categories在Redis 6.0之后才有，tips、key specs及subcommands在Redis 7.0之后才有
+func parseCommandInfo(r *Reply) (*CommandInfo, error) {
+	if r.Type != MultiReply || len(r.Children) < 6 {
+		return nil, errors.New("reply is not formatted as a COMMAND INFO entry")
+	}
+	name, err := r.Children[0].Str()
+	if err != nil {
+		return nil, err
+	}
+	info := &CommandInfo{Name: strings.ToLower(name)}
+	if info.Arity, err = r.Children[1].Int(); err != nil {
+		return nil, err
+	}
+	if info.Flags, err = r.Children[2].List(); err != nil {
+		return nil, err
+	}
+	if info.FirstKey, err = r.Children[3].Int(); err != nil {
+		return nil, err
+	}
+	if info.LastKey, err = r.Children[4].Int(); err != nil {
+		return nil, err
+	}
+	if info.Step, err = r.Children[5].Int(); err != nil {
+		return nil, err
+	}
+	if len(r.Children) > 6 {
+		if info.ACLCategories, err = r.Children[6].List(); err != nil {
+			return nil, err
+		}
+	}
+	if len(r.Children) > 8 {
+		for _, spec := range r.Children[8].Children {
+			ks, err := parseCommandKeySpec(spec)
+			if err != nil {
+				return nil, err
+			}
+			info.KeySpecs = append(info.KeySpecs, ks)
+		}
+	}
+	if len(r.Children) > 9 && len(r.Children[9].Children) > 0 {
+		info.Subcommands = make(map[string]*CommandInfo, len(r.Children[9].Children))
+		for _, sub := range r.Children[9].Children {
+			s, err := parseCommandInfo(sub)
+			if err != nil {
+				return nil, err
+			}
+			_, subName, _ := strings.Cut(s.Name, "|")
+			info.Subcommands[subName] = s
+		}
+	}
+	return info, nil
+}
+
+func parseCommandKeySpec(r *Reply) (CommandKeySpec, error) {
+	ks := CommandKeySpec{}
+	m, err := kvReplies(r)
+	if err != nil {
+		return ks, err
+	}
+	if flags, ok := m["flags"]; ok {
+		if ks.Flags, err = flags.List(); err != nil {
+			return ks, err
+		}
+	}
+	if bs, ok := m["begin_search"]; ok {
+		typ, spec, err := keySpecPart(bs)
+		if err != nil {
+			return ks, err
+		}
+		switch typ {
+		case "index":
+			ks.BeginIndex = int(kvInt(spec, "index"))
+		case "keyword":
+			ks.BeginKeyword = kvStr(spec, "keyword")
+			ks.KeywordStartFrom = int(kvInt(spec, "startfrom"))
+		default:
+			ks.Unknown = true
+		}
+	}
+	if fk, ok := m["find_keys"]; ok {
+		typ, spec, err := keySpecPart(fk)
+		if err != nil {
+			return ks, err
+		}
+		switch typ {
+		case "range":
+			ks.LastKey = int(kvInt(spec, "lastkey"))
+			ks.KeyStep = int(kvInt(spec, "keystep"))
+			ks.Limit = int(kvInt(spec, "limit"))
+		case "keynum":
+			ks.KeyNum = true
+			ks.KeyNumIdx = int(kvInt(spec, "keynumidx"))
+			ks.FirstKey = int(kvInt(spec, "firstkey"))
+			ks.KeyStep = int(kvInt(spec, "keystep"))
+		default:
+			ks.Unknown = true
+		}
+	}
+	return ks, nil
+}
+
+// 解析begin_search/find_keys: {type: ..., spec: {...}}
+func keySpecPart(r *Reply) (string, map[string]*Reply, error) {
+	m, err := kvReplies(r)
+	if err != nil {
+		return "", nil, err
+	}
+	spec := make(map[string]*Reply)
+	if sr, ok := m["spec"]; ok && sr.Type == MultiReply {
+		if spec, err = kvReplies(sr); err != nil {
+			return "", nil, err
+		}
+	}
+	return kvStr(m, "type"), spec, nil
+}
+
+// COMMAND DOCS中的一个命令(Redis 7.0+)
+type CommandDoc struct {
+	Summary     string
+	Since       string
+	Group       string
+	Complexity  string
+	DocFlags    []string
+	// key为子命令的完整名称，如 config|get
+	Subcommands map[string]CommandDoc
+}
+
+// 返回命令的文档，names为空时返回所有命令，key为小写的命令名
+func (g *Gedis)CommandDocs(names... string) (map[string]CommandDoc, error) {
+	m, err := kvReplies(g.Cmd("COMMAND", "DOCS", names))
+	if err != nil {
+		return nil, err
+	}
+	return parseCommandDocs(m)
+}
+
+func parseCommandDocs(m map[string]*Reply) (map[string]CommandDoc, error) {
+	docs := make(map[string]CommandDoc, len(m))
+	for name, r := range m {
+		dm, err := kvReplies(r)
+		if err != nil {
+			return nil, err
+		}
+		doc := CommandDoc{
+			Summary: kvStr(dm, "summary"),
+			Since: kvStr(dm, "since"),
+			Group: kvStr(dm, "group"),
+			Complexity: kvStr(dm, "complexity"),
+		}
+		if flags, ok := dm["doc_flags"]; ok {
+			doc.DocFlags, _ = flags.List()
+		}
+		if subs, ok := dm["subcommands"]; ok {
+			sm, err := kvReplies(subs)
+			if err != nil {
+				return nil, err
+			}
+			if doc.Subcommands, err = parseCommandDocs(sm); err != nil {
+				return nil, err
+			}
+		}
+		docs[strings.ToLower(name)] = doc
+	}
+	return docs, nil
+}
+
+func (g *Gedis)CommandCount() (int64, error) {
+	return g.Cmd("COMMAND", "COUNT").Int64()
+}
+
+// 由服务端解析命令中的key，用于key位置无法通过元数据确定的命令
+func (g *Gedis)CommandGetKeys(cmd string, args... interface{}) ([]string, error) {
+	return g.Cmd("COMMAND", "GETKEYS", cmd, args).List()
+}
+
+// 命令元数据的注册表，初始内容为内置的默认表，可通过Load从服务端加载完整的元数据
+type CommandRegistry struct {
+	sync.RWMutex
+	commands map[string]*CommandInfo
+	// 保证创建ShardedGedis时只自动加载一次
+	autoLoad sync.Once
+}
+
+// 默认的注册表，ShardedGedis.Cmd通过它查找命令中的key；第一次创建ShardedGedis时从服务端加载
+var DefaultCommands = NewCommandRegistry()
+
+func NewCommandRegistry() *CommandRegistry {
+	r := &CommandRegistry{commands: make(map[string]*CommandInfo, len(builtinCommands))}
+	for i := range builtinCommands {
+		c := builtinCommands[i]
+		r.commands[c.Name] = &c
+	}
+	return r
+}
+
+// 通过COMMAND INFO及COMMAND DOCS从服务端加载元数据，覆盖已有的条目；
+// 不支持COMMAND DOCS的服务端(Redis 7.0以下)只加载COMMAND INFO
+func (r *CommandRegistry)Load(g *Gedis) error {
+	infos, err := g.CommandInfo()
+	if err != nil {
+		return err
+	}
+	docs, _ := g.CommandDocs()
+	r.Lock()
+	defer r.Unlock()
+	for _, info := range infos {
+		if doc, ok := docs[info.Name]; ok {
+			info.Summary = doc.Summary
+			info.Since = doc.Since
+			info.Group = doc.Group
+			for name, sub := range info.Subcommands {
+				if sd, ok := doc.Subcommands[sub.Name]; ok {
+					info.Subcommands[name].Summary = sd.Summary
+					info.Subcommands[name].Since = sd.Since
+					info.Subcommands[name].Group = sd.Group
+				}
+			}
+		}
+		r.commands[info.Name] = info
+	}
+	return nil
+}
+
+// 第一次调用时通过g加载元数据，失败时(如COMMAND被ACL禁止)继续使用内置的默认表，之后的调用不做任何事
+func (r *CommandRegistry)loadOnce(g *Gedis) {
+	r.autoLoad.Do(func() {
+		r.Load(g)
+	})
+}
+
+// 添加或覆盖一个命令，用于模块命令等
+func (r *CommandRegistry)Register(info *CommandInfo) {
+	r.Lock()
+	defer r.Unlock()
+	r.commands[strings.ToLower(info.Name)] = info
+}
+
+// 返回命令的元数据，有子命令时根据第一个参数返回子命令的元数据，未知的命令返回nil
+func (r *CommandRegistry)Lookup(argv []string) *CommandInfo {
+	if len(argv) == 0 {
+		return nil
+	}
+	r.RLock()
+	defer r.RUnlock()
+	info := r.commands[strings.ToLower(argv[0])]
+	if info != nil && len(info.Subcommands) > 0 && len(argv) > 1 {
+		if sub, ok := info.Subcommands[strings.ToLower(argv[1])]; ok {
+			return sub
+		}
+	}
+	return info
+}
+
+// 将命令及参数展开为与发送给服务端时相同的字符串列表
+func commandArgv(cmd string, args []interface{}) ([]string, error) {
+	req := make([]interface{}, 0, len(args) + 1)
+	req = append(req, cmd)
+	req = append(req, args...)
+	m, err := resp.ReadMessage(bytes.NewReader(resp.AppendArbitraryAsFlattenedStrings(nil, req)))
+	if err != nil {
+		return nil, err
+	}
+	items, err := m.Array()
+	if err != nil {
+		return nil, err
+	}
+	argv := make([]string, len(items))
+	for i, item := range items {
+		if argv[i], err = item.Str(); err != nil {
+			return nil, err
+		}
+	}
+	return argv, nil
+}
+
+func cmdInfo(name string, arity int, flags string, first, last, step int, categories string, specs... CommandKeySpec) CommandInfo {
+	return CommandInfo{
+		Name: name,
+		Arity: arity,
+		Flags: strings.Fields(flags),
+		FirstKey: first,
+		LastKey: last,
+		Step: step,
+		ACLCategories: strings.Fields(categories),
+		KeySpecs: specs,
+	}
+}
+
+// 从下标index开始、共有numkeys个key的key spec，用于EVAL、ZUNION等
+func keyNumSpec(index int) CommandKeySpec {
+	return CommandKeySpec{BeginIndex: index, KeyNum: true, KeyNumIdx: 0, FirstKey: 1, KeyStep: 1}
+}
+
+// 内置的默认表，在无法执行COMMAND的环境(如被ACL禁止)中使用
+var builtinCommands = []CommandInfo{
+	cmdInfo("get", 2, "readonly fast", 1, 1, 1, "@read @string @fast"),
+	cmdInfo("set", -3, "write denyoom", 1, 1, 1, "@write @string @slow"),
+	cmdInfo("setnx", 3, "write denyoom fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("setex", 4, "write denyoom", 1, 1, 1, "@write @string @slow"),
+	cmdInfo("psetex", 4, "write denyoom", 1, 1, 1, "@write @string @slow"),
+	cmdInfo("getex", -2, "write fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("getdel", 2, "write fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("getset", 3, "write denyoom fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("incr", 2, "write denyoom fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("incrby", 3, "write denyoom fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("incrbyfloat", 3, "write denyoom fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("decr", 2, "write denyoom fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("decrby", 3, "write denyoom fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("mget", -2, "readonly fast", 1, -1, 1, "@read @string @fast"),
+	cmdInfo("mset", -3, "write denyoom", 1, -1, 2, "@write @string @slow"),
+	cmdInfo("msetnx", -3, "write denyoom", 1, -1, 2, "@write @string @slow"),
+	cmdInfo("append", 3, "write denyoom fast", 1, 1, 1, "@write @string @fast"),
+	cmdInfo("getrange", 4, "readonly", 1, 1, 1, "@read @string @slow"),
+	cmdInfo("setrange", 4, "write denyoom", 1, 1, 1, "@write @string @slow"),
+	cmdInfo("strlen", 2, "readonly fast", 1, 1, 1, "@read @string @fast"),
+	cmdInfo("lcs", -3, "readonly", 1, 2, 1, "@read @string @slow"),
+
+	cmdInfo("del", -2, "write", 1, -1, 1, "@keyspace @write @slow"),
+	cmdInfo("unlink", -2, "write fast", 1, -1, 1, "@keyspace @write @fast"),
+	cmdInfo("exists", -2, "readonly fast", 1, -1, 1, "@keyspace @read @fast"),
+	cmdInfo("touch", -2, "readonly fast", 1, -1, 1, "@keyspace @read @fast"),
+	cmdInfo("type", 2, "readonly fast", 1, 1, 1, "@keyspace @read @fast"),
+	cmdInfo("expire", -3, "write fast", 1, 1, 1, "@keyspace @write @fast"),
+	cmdInfo("pexpire", -3, "write fast", 1, 1, 1, "@keyspace @write @fast"),
+	cmdInfo("expireat", -3, "write fast", 1, 1, 1, "@keyspace @write @fast"),
+	cmdInfo("pexpireat", -3, "write fast", 1, 1, 1, "@keyspace @write @fast"),
+	cmdInfo("ttl", 2, "readonly fast", 1, 1, 1, "@keyspace @read @fast"),
+	cmdInfo("pttl", 2, "readonly fast", 1, 1, 1, "@keyspace @read @fast"),
+	cmdInfo("persist", 2, "write fast", 1, 1, 1, "@keyspace @write @fast"),
+	cmdInfo("rename", 3, "write", 1, 2, 1, "@keyspace @write @slow"),
+	cmdInfo("renamenx", 3, "write fast", 1, 2, 1, "@keyspace @write @fast"),
+	cmdInfo("copy", -3, "write denyoom", 1, 2, 1, "@keyspace @write @slow"),
+	cmdInfo("dump", 2, "readonly", 1, 1, 1, "@keyspace @read @slow"),
+	cmdInfo("restore", -4, "write denyoom", 1, 1, 1, "@keyspace @write @slow @dangerous"),
+	cmdInfo("sort", -2, "write denyoom movablekeys", 1, 0, 0, "@write @set @sortedset @list @slow @dangerous",
+		CommandKeySpec{BeginIndex: 1, KeyStep: 1}),
+	cmdInfo("sort_ro", -2, "readonly movablekeys", 1, 0, 0, "@read @set @sortedset @list @slow @dangerous",
+		CommandKeySpec{BeginIndex: 1, KeyStep: 1}),
+
+	cmdInfo("hset", -4, "write denyoom fast", 1, 1, 1, "@write @hash @fast"),
+	cmdInfo("hsetnx", 4, "write denyoom fast", 1, 1, 1, "@write @hash @fast"),
+	cmdInfo("hget", 3, "readonly fast", 1, 1, 1, "@read @hash @fast"),
+	cmdInfo("hmget", -3, "readonly fast", 1, 1, 1, "@read @hash @fast"),
+	cmdInfo("hgetall", 2, "readonly", 1, 1, 1, "@read @hash @slow"),
+	cmdInfo("hdel", -3, "write fast", 1, 1, 1, "@write @hash @fast"),
+	cmdInfo("hexists", 3, "readonly fast", 1, 1, 1, "@read @hash @fast"),
+	cmdInfo("hincrby", 4, "write denyoom fast", 1, 1, 1, "@write @hash @fast"),
+	cmdInfo("hincrbyfloat", 4, "write denyoom fast", 1, 1, 1, "@write @hash @fast"),
+	cmdInfo("hkeys", 2, "readonly", 1, 1, 1, "@read @hash @slow"),
+	cmdInfo("hvals", 2, "readonly", 1, 1, 1, "@read @hash @slow"),
+	cmdInfo("hlen", 2, "readonly fast", 1, 1, 1, "@read @hash @fast"),
+	cmdInfo("hstrlen", 3, "readonly fast", 1, 1, 1, "@read @hash @fast"),
+	cmdInfo("hscan", -3, "readonly", 1, 1, 1, "@read @hash @slow"),
+
+	cmdInfo("lpush", -3, "write denyoom fast", 1, 1, 1, "@write @list @fast"),
+	cmdInfo("rpush", -3, "write denyoom fast", 1, 1, 1, "@write @list @fast"),
+	cmdInfo("lpop", -2, "write fast", 1, 1, 1, "@write @list @fast"),
+	cmdInfo("rpop", -2, "write fast", 1, 1, 1, "@write @list @fast"),
+	cmdInfo("llen", 2, "readonly fast", 1, 1, 1, "@read @list @fast"),
+	cmdInfo("lrange", 4, "readonly", 1, 1, 1, "@read @list @slow"),
+	cmdInfo("lindex", 3, "readonly", 1, 1, 1, "@read @list @slow"),
+	cmdInfo("lset", 4, "write denyoom", 1, 1, 1, "@write @list @slow"),
+	cmdInfo("lrem", 4, "write", 1, 1, 1, "@write @list @slow"),
+	cmdInfo("ltrim", 4, "write", 1, 1, 1, "@write @list @slow"),
+	cmdInfo("lmove", 5, "write denyoom", 1, 2, 1, "@write @list @slow"),
+	cmdInfo("blpop", -3, "write blocking", 1, -2, 1, "@write @list @slow @blocking"),
+	cmdInfo("brpop", -3, "write blocking", 1, -2, 1, "@write @list @slow @blocking"),
+	cmdInfo("blmove", 6, "write denyoom blocking", 1, 2, 1, "@write @list @slow @blocking"),
+	cmdInfo("lmpop", -4, "write movablekeys", 0, 0, 0, "@write @list @slow", keyNumSpec(1)),
+	cmdInfo("blmpop", -5, "write blocking movablekeys", 0, 0, 0, "@write @list @slow @blocking", keyNumSpec(2)),
+
+	cmdInfo("sadd", -3, "write denyoom fast", 1, 1, 1, "@write @set @fast"),
+	cmdInfo("srem", -3, "write fast", 1, 1, 1, "@write @set @fast"),
+	cmdInfo("smembers", 2, "readonly", 1, 1, 1, "@read @set @slow"),
+	cmdInfo("sismember", 3, "readonly fast", 1, 1, 1, "@read @set @fast"),
+	cmdInfo("smismember", -3, "readonly fast", 1, 1, 1, "@read @set @fast"),
+	cmdInfo("scard", 2, "readonly fast", 1, 1, 1, "@read @set @fast"),
+	cmdInfo("spop", -2, "write fast", 1, 1, 1, "@write @set @fast"),
+	cmdInfo("srandmember", -2, "readonly", 1, 1, 1, "@read @set @slow"),
+	cmdInfo("smove", 4, "write fast", 1, 2, 1, "@write @set @fast"),
+	cmdInfo("sinter", -2, "readonly", 1, -1, 1, "@read @set @slow"),
+	cmdInfo("sunion", -2, "readonly", 1, -1, 1, "@read @set @slow"),
+	cmdInfo("sdiff", -2, "readonly", 1, -1, 1, "@read @set @slow"),
+	cmdInfo("sscan", -3, "readonly", 1, 1, 1, "@read @set @slow"),
+
+	cmdInfo("zadd", -4, "write denyoom fast", 1, 1, 1, "@write @sortedset @fast"),
+	cmdInfo("zrem", -3, "write fast", 1, 1, 1, "@write @sortedset @fast"),
+	cmdInfo("zscore", 3, "readonly fast", 1, 1, 1, "@read @sortedset @fast"),
+	cmdInfo("zmscore", -3, "readonly fast", 1, 1, 1, "@read @sortedset @fast"),
+	cmdInfo("zincrby", 4, "write denyoom fast", 1, 1, 1, "@write @sortedset @fast"),
+	cmdInfo("zcard", 2, "readonly fast", 1, 1, 1, "@read @sortedset @fast"),
+	cmdInfo("zcount", 4, "readonly fast", 1, 1, 1, "@read @sortedset @fast"),
+	cmdInfo("zrank", -3, "readonly fast", 1, 1, 1, "@read @sortedset @fast"),
+	cmdInfo("zrevrank", -3, "readonly fast", 1, 1, 1, "@read @sortedset @fast"),
+	cmdInfo("zrange", -4, "readonly", 1, 1, 1, "@read @sortedset @slow"),
+	cmdInfo("zrangestore", -5, "write denyoom", 1, 2, 1, "@write @sortedset @slow"),
+	cmdInfo("zpopmin", -2, "write fast", 1, 1, 1, "@write @sortedset @fast"),
+	cmdInfo("zpopmax", -2, "write fast", 1, 1, 1, "@write @sortedset @fast"),
+	cmdInfo("bzpopmin", -3, "write blocking fast", 1, -2, 1, "@write @sortedset @fast @blocking"),
+	cmdInfo("bzpopmax", -3, "write blocking fast", 1, -2, 1, "@write @sortedset @fast @blocking"),
+	cmdInfo("zunion", -3, "readonly movablekeys", 0, 0, 0, "@read @sortedset @slow", keyNumSpec(1)),
+	cmdInfo("zinter", -3, "readonly movablekeys", 0, 0, 0, "@read @sortedset @slow", keyNumSpec(1)),
+	cmdInfo("zdiff", -3, "readonly movablekeys", 0, 0, 0, "@read @sortedset @slow", keyNumSpec(1)),
+	cmdInfo("zmpop", -4, "write movablekeys", 0, 0, 0, "@write @sortedset @slow", keyNumSpec(1)),
+	cmdInfo("bzmpop", -5, "write blocking movablekeys", 0, 0, 0, "@write @sortedset @slow @blocking", keyNumSpec(2)),
+	cmdInfo("zscan", -3, "readonly", 1, 1, 1, "@read @sortedset @slow"),
+
+	cmdInfo("xadd", -5, "write denyoom fast", 1, 1, 1, "@write @stream @fast"),
+	cmdInfo("xlen", 2, "readonly fast", 1, 1, 1, "@read @stream @fast"),
+	cmdInfo("xrange", -4, "readonly", 1, 1, 1, "@read @stream @slow"),
+	cmdInfo("xrevrange", -4, "readonly", 1, 1, 1, "@read @stream @slow"),
+	cmdInfo("xdel", -3, "write fast", 1, 1, 1, "@write @stream @fast"),
+	cmdInfo("xtrim", -4, "write", 1, 1, 1, "@write @stream @slow"),
+	cmdInfo("xack", -4, "write fast", 1, 1, 1, "@write @stream @fast"),
+	cmdInfo("xread", -4, "readonly blocking movablekeys", 0, 0, 0, "@read @stream @slow @blocking",
+		CommandKeySpec{BeginKeyword: "STREAMS", KeywordStartFrom: 1, LastKey: -1, KeyStep: 1, Limit: 2}),
+	cmdInfo("xreadgroup", -7, "write blocking movablekeys", 0, 0, 0, "@write @stream @slow @blocking",
+		CommandKeySpec{BeginKeyword: "STREAMS", KeywordStartFrom: 4, LastKey: -1, KeyStep: 1, Limit: 2}),
+
+	cmdInfo("pfadd", -2, "write denyoom fast", 1, 1, 1, "@write @hyperloglog @fast"),
+	cmdInfo("pfcount", -2, "readonly", 1, -1, 1, "@read @hyperloglog @slow"),
+	cmdInfo("pfmerge", -2, "write denyoom", 1, -1, 1, "@write @hyperloglog @slow"),
+	cmdInfo("setbit", 4, "write denyoom", 1, 1, 1, "@write @bitmap @slow"),
+	cmdInfo("getbit", 3, "readonly fast", 1, 1, 1, "@read @bitmap @fast"),
+	cmdInfo("bitcount", -2, "readonly", 1, 1, 1, "@read @bitmap @slow"),
+	cmdInfo("bitpos", -3, "readonly", 1, 1, 1, "@read @bitmap @slow"),
+	cmdInfo("geoadd", -5, "write denyoom", 1, 1, 1, "@write @geo @slow"),
+	cmdInfo("geopos", -2, "readonly", 1, 1, 1, "@read @geo @slow"),
+	cmdInfo("geodist", -4, "readonly", 1, 1, 1, "@read @geo @slow"),
+	cmdInfo("geosearch", -7, "readonly", 1, 1, 1, "@read @geo @slow"),
+
+	cmdInfo("eval", -3, "noscript stale skip_monitor may_replicate no_mandatory_keys movablekeys", 0, 0, 0, "@slow @scripting", keyNumSpec(2)),
+	cmdInfo("evalsha", -3, "noscript stale skip_monitor may_replicate no_mandatory_keys movablekeys", 0, 0, 0, "@slow @scripting", keyNumSpec(2)),
+	cmdInfo("eval_ro", -3, "noscript stale skip_monitor no_mandatory_keys readonly movablekeys", 0, 0, 0, "@slow @scripting", keyNumSpec(2)),
+	cmdInfo("evalsha_ro", -3, "noscript stale skip_monitor no_mandatory_keys readonly movablekeys", 0, 0, 0, "@slow @scripting", keyNumSpec(2)),
+	cmdInfo("fcall", -3, "noscript stale skip_monitor may_replicate no_mandatory_keys movablekeys", 0, 0, 0, "@slow @scripting", keyNumSpec(2)),
+	cmdInfo("fcall_ro", -3, "noscript stale skip_monitor no_mandatory_keys readonly movablekeys", 0, 0, 0, "@slow @scripting", keyNumSpec(2)),
+
+	cmdInfo("ping", -1, "fast", 0, 0, 0, "@fast @connection"),
+	cmdInfo("echo", 2, "fast", 0, 0, 0, "@fast @connection"),
+	cmdInfo("publish", 3, "pubsub loading stale fast may_replicate", 0, 0, 0, "@pubsub @fast"),
+	cmdInfo("subscribe", -2, "pubsub noscript loading stale", 0, 0, 0, "@pubsub @slow"),
+	cmdInfo("psubscribe", -2, "pubsub noscript loading stale", 0, 0, 0, "@pubsub @slow"),
+}
@@ -0,0 +1,137 @@
+package gedis
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCommandKeySpecKeys(t *testing.T) {
+	cases := []struct {
+		name string
+		spec CommandKeySpec
+		argv string
+		keys []string
+		ok   bool
+	}{
+		{"index range", CommandKeySpec{BeginIndex: 1, LastKey: -1, KeyStep: 2}, "MSET a 1 b 2", []string{"a", "b"}, true},
+		{"index single", CommandKeySpec{BeginIndex: 1}, "GET a", []string{"a"}, true},
+		{"index out of range", CommandKeySpec{BeginIndex: 3}, "GET a", nil, true},
+		{"keyword with limit", CommandKeySpec{BeginKeyword: "STREAMS", KeywordStartFrom: 1, LastKey: -1, KeyStep: 1, Limit: 2},
+			"XREAD COUNT 10 streams a b 0 0", []string{"a", "b"}, true},
+		{"keyword missing", CommandKeySpec{BeginKeyword: "STREAMS", KeywordStartFrom: 1, LastKey: -1, KeyStep: 1, Limit: 2},
+			"XREAD COUNT 10", nil, true},
+		{"keyword from end", CommandKeySpec{BeginKeyword: "STORE", KeywordStartFrom: -2},
+			"GEORADIUS k 0 0 1 km STORE dest", []string{"dest"}, true},
+		{"keyword from end missing", CommandKeySpec{BeginKeyword: "STORE", KeywordStartFrom: -2},
+			"GEORADIUS k 0 0 1 km", nil, true},
+		{"keyword start beyond argv", CommandKeySpec{BeginKeyword: "STORE", KeywordStartFrom: -20}, "GEORADIUS k", nil, true},
+		{"keynum", keyNumSpec(2), "EVAL script 2 k1 k2 arg", []string{"k1", "k2"}, true},
+		{"keynum zero", keyNumSpec(2), "EVAL script 0 arg", []string{}, true},
+		{"keynum more than argv", keyNumSpec(1), "ZUNION 3 a b", []string{"a", "b"}, true},
+		{"keynum missing", keyNumSpec(2), "EVAL script", nil, true},
+		{"keynum not a number", keyNumSpec(2), "EVAL script x k1", nil, false},
+		{"unknown", CommandKeySpec{Unknown: true}, "MIGRATE h p k 0 0", nil, false},
+	}
+	for _, c := range cases {
+		keys, ok := c.spec.keys(strings.Fields(c.argv))
+		if ok != c.ok || !reflect.DeepEqual(keys, c.keys) {
+			t.Errorf("%s: keys(%q) = %q, %v, want %q, %v", c.name, c.argv, keys, ok, c.keys, c.ok)
+		}
+	}
+}
+
+func TestCommandInfoKeys(t *testing.T) {
+	r := NewCommandRegistry()
+	cases := []struct {
+		argv string
+		keys []string
+	}{
+		{"GET a", []string{"a"}},
+		{"MGET a b c", []string{"a", "b", "c"}},
+		{"MSET a 1 b 2", []string{"a", "b"}},
+		{"BLPOP a b 0", []string{"a", "b"}},
+		{"EVALSHA sha 1 k arg", []string{"k"}},
+		{"XREADGROUP GROUP g c COUNT 1 STREAMS s1 s2 > >", []string{"s1", "s2"}},
+		{"PING", nil},
+	}
+	for _, c := range cases {
+		argv := strings.Fields(c.argv)
+		info := r.Lookup(argv)
+		if info == nil {
+			t.Fatalf("Lookup(%q) = nil", c.argv)
+		}
+		keys, ok := info.Keys(argv)
+		if !ok || !reflect.DeepEqual(keys, c.keys) {
+			t.Errorf("Keys(%q) = %q, %v, want %q", c.argv, keys, ok, c.keys)
+		}
+	}
+}
+
+func TestCommandRegistryLookup(t *testing.T) {
+	r := NewCommandRegistry()
+	if info := r.Lookup([]string{"get", "k"}); info == nil || info.Name != "get" || !info.ReadOnly() || !info.Retryable() {
+		t.Errorf("Lookup(get) = %+v", info)
+	}
+	if info := r.Lookup([]string{"BLPOP", "k", "0"}); info == nil || info.Retryable() {
+		t.Errorf("Lookup(BLPOP) = %+v, want a non-retryable command", info)
+	}
+	if info := r.Lookup([]string{"NOSUCHCMD"}); info != nil {
+		t.Errorf("Lookup(NOSUCHCMD) = %+v, want nil", info)
+	}
+	if info := r.Lookup(nil); info != nil {
+		t.Errorf("Lookup(nil) = %+v, want nil", info)
+	}
+
+	config := &CommandInfo{Name: "CONFIG", Arity: -2, Subcommands: map[string]*CommandInfo{
+		"get": {Name: "config|get", Arity: -3, Flags: []string{"admin"}},
+	}}
+	r.Register(config)
+	if info := r.Lookup([]string{"config", "GET", "maxmemory"}); info == nil || info.Name != "config|get" {
+		t.Errorf("Lookup(config GET) = %+v, want config|get", info)
+	}
+	// 未知的子命令返回container本身
+	if info := r.Lookup([]string{"CONFIG", "NOSUCH"}); info != config {
+		t.Errorf("Lookup(CONFIG NOSUCH) = %+v, want config", info)
+	}
+	if info := r.Lookup([]string{"CONFIG"}); info != config {
+		t.Errorf("Lookup(CONFIG) = %+v, want config", info)
+	}
+}
+
+func TestCommandRegistryLoad(t *testing.T) {
+	info := respArray(respBulk("mycmd"), ":-2\r\n", respArray("+readonly\r\n"), ":1\r\n", ":1\r\n", ":1\r\n")
+	g, _ := newFakeGedis(respArray(info), "-ERR unknown subcommand 'DOCS'\r\n")
+	r := NewCommandRegistry()
+	if err := r.Load(g); err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	argv := []string{"MYCMD", "k"}
+	c := r.Lookup(argv)
+	if c == nil || !c.ReadOnly() {
+		t.Fatalf("Lookup(MYCMD) = %+v", c)
+	}
+	if keys, ok := c.Keys(argv); !ok || !reflect.DeepEqual(keys, []string{"k"}) {
+		t.Errorf("Keys = %q, %v", keys, ok)
+	}
+	if r.Lookup([]string{"GET", "k"}) == nil {
+		t.Error("builtin command lost after Load")
+	}
+}
+
+func TestCommandRegistryLoadOnceFallback(t *testing.T) {
+	g, fc := newFakeGedis("-NOPERM this user has no permissions to run the 'command' command\r\n")
+	r := NewCommandRegistry()
+	r.loadOnce(g)
+	if r.Lookup([]string{"GET", "k"}) == nil {
+		t.Fatal("builtin table lost after a failed Load")
+	}
+	n := fc.written.Len()
+	if n == 0 {
+		t.Fatal("loadOnce did not send COMMAND")
+	}
+	r.loadOnce(g)
+	if fc.written.Len() != n {
+		t.Errorf("second loadOnce sent %q", fc.written.String()[n:])
+	}
+}
@@ -5,11 +5,13 @@ import (
 	"time"
 	"bufio"
 	"redis/resp"
+	"strconv"
 	"strings"
 	"errors"
+	"sync/atomic"
 )
 
-var LoadingError string = errors.New("server is busy to loading data")
+var LoadingError error = errors.New("server is busy to loading data")
 
 const (
 	bufSize int = 4096
@@ -31,6 +33,12 @@ type Connection struct {
 	writeBuf  []byte
 
 	completed []*Reply
+
+	// 是否开启Reply复用模式，参见SetReplyPool
+	replyPool bool
+
+	// Close之后为true，连接池据此丢弃不可用的连接
+	closed    atomic.Bool
 }
 
 type request struct {
@@ -48,7 +56,7 @@ func Dial(host string, port int) (*Connection, error) {
 }
 
 func DialWithTimeout(host string, port int, timeout time.Duration) (*Connection, error) {
-	conn, err := net.DialTimeout("tcp", host + ":" + port, timeout)
+	conn, err := net.DialTimeout("tcp", host + ":" + strconv.Itoa(port), timeout)
 	if err != nil {
 		return nil, err
 	}
@@ -62,9 +70,14 @@ func DialWithTimeout(host string, port int, timeout time.Duration) (*Connection,
 
 // 关闭连接
 func (c *Connection) Close() error {
+	c.closed.Store(true)
 	return c.Conn.Close()
 }
 
+func (c *Connection) isClosed() bool {
+	return c.closed.Load()
+}
+
 // 执行Redis命令
 func (c *Connection) Exec(cmd string, args...interface{}) *Reply {
 	err := c.writeRequest(&request{cmd, args})
@@ -74,27 +87,92 @@ func (c *Connection) Exec(cmd string, args...interface{}) *Reply {
 	return c.ReadReply()
 }
 
+// 执行阻塞命令(BLPOP、XREAD BLOCK等)，block为命令在服务端的阻塞时长：
+// 读超时在Connection.timeout的基础上再延长block，避免服务端正常阻塞等待时连接被读超时关闭；
+// block为0表示服务端无限阻塞，此时取消读超时
+func (c *Connection) ExecBlocking(block time.Duration, cmd string, args...interface{}) *Reply {
+	err := c.writeRequest(&request{cmd, args})
+	if err != nil {
+		return &Reply{Type:ErrorReply, Err:err}
+	}
+	return c.ReadReplyBlocking(block)
+}
+
+// 读取阻塞命令的返回值，block的含义与ExecBlocking相同
+func (c *Connection) ReadReplyBlocking(block time.Duration) *Reply {
+	if block == 0 {
+		c.Conn.SetReadDeadline(time.Time{})
+	} else if c.timeout != 0 {
+		c.Conn.SetReadDeadline(time.Now().Add(c.timeout + block))
+	}
+	return c.parse()
+}
+
 func (c *Connection)ReadReply() *Reply {
 	c.setReadTimeout()
 	return c.parse()
 }
 
+// 开启/关闭Reply复用模式：开启后返回的Reply及其底层[]byte来自sync.Pool，
+// 用完后需调用Reply.Release()还回，以降低大量MGET/LRANGE等返回值带来的GC压力。
+// MGet、HMGet、HGetAll、LRange、SMembers、ZRange等返回集合的命令方法在拷贝出结果后会自动Release，
+// Cmd、Get等直接返回*Reply的方法需要调用方自行Release，其它命令方法不Release，Reply由GC回收
+func (c *Connection) SetReplyPool(enabled bool) {
+	c.replyPool = enabled
+}
+
+// 只发送命令而不读取返回值，用于CLIENT REPLY OFF/SKIP等服务端不会返回的场景
+func (c *Connection) Send(cmd string, args...interface{}) error {
+	return c.writeRequest(&request{cmd, args})
+}
+
+// 将命令添加到待发送队列，通过Flush一次性发送
 func (c *Connection) Append(cmd string, args...interface{}) {
 	c.pending = append(c.pending, &request{cmd, args})
 }
 
+// 待发送队列中的命令数
+func (c *Connection) Pending() int {
+	return len(c.pending)
+}
+
+// 清空待发送队列
+func (c *Connection) Discard() {
+	c.pending = nil
+}
+
+// 一次性发送待发送队列中的所有命令，并按顺序读取每个命令的返回值；
+// 发送失败时每个命令的返回值都是该错误
+func (c *Connection) Flush() []*Reply {
+	requests := c.pending
+	c.pending = nil
+	c.completed = make([]*Reply, len(requests))
+	if err := c.writeRequest(requests...); err != nil {
+		for i := range c.completed {
+			c.completed[i] = &Reply{Type:ErrorReply, Err:err}
+		}
+		return c.completed
+	}
+	for i := range c.completed {
+		c.completed[i] = c.ReadReply()
+	}
+	return c.completed
+}
+
 func (c *Connection) writeRequest(requests...*request) error {
 	c.setWriteTimeout()
+	// 复用writeBuf，避免每个请求都分配新的缓冲区，多个请求合并为一次写
+	c.writeBuf = c.writeBuf[:0]
 	for i := range requests {
 		req := make([]interface{}, 0, len(requests[i].args) + 1)
 		req = append(req, requests[i].cmd)
 		req = append(req, requests[i].args...)
-		buf := resp.AppendArbitraryAsFlattenedStrings()
-		_, err := c.Conn.Write(buf)
-		if err != nil {
-			c.Close()
-			return err
-		}
+		c.writeBuf = resp.AppendArbitraryAsFlattenedStrings(c.writeBuf, req)
+	}
+	_, err := c.Conn.Write(c.writeBuf)
+	if err != nil {
+		c.Close()
+		return err
 	}
 	return nil
 }
@@ -112,6 +190,16 @@ func (c *Connection) setWriteTimeout() {
 }
 
 func (c *Connection)parse() *Reply {
+	if c.replyPool {
+		r, err := readPooledReply(c.reader)
+		if err != nil {
+			if t, ok := err.(*net.OpError); !ok || t.Timeout() {
+				c.Close()
+			}
+			return &Reply{Type:ErrorReply, Err:err}
+		}
+		return r
+	}
 	m, err := resp.ReadMessage(c.reader)
 	if err != nil {
 		if t, ok := err.(*net.OpError); !ok || t.Timeout() {
@@ -0,0 +1,288 @@
+package gedis
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Redis Functions(7.0+)相关的命令
+
+// 加载函数库，replace为true时覆盖同名库，返回库名
+func (g *Gedis)FunctionLoad(code string, replace bool) (string, error) {
+	if replace {
+		return g.Cmd("FUNCTION", "LOAD", "REPLACE", code).Str()
+	}
+	return g.Cmd("FUNCTION", "LOAD", code).Str()
+}
+
+// FUNCTION LIST返回的一个函数库
+type Library struct {
+	Name      string
+	Engine    string
+	Functions []LibraryFunction
+	// 只在WITHCODE时有值
+	Code      string
+}
+
+// 函数库中的一个函数
+type LibraryFunction struct {
+	Name        string
+	Description string
+	// 如no-writes、allow-oom等
+	Flags       []string
+}
+
+// FUNCTION LIST的可选参数，Pattern为空时返回全部函数库
+type FunctionListOptions struct {
+	Pattern  string
+	WithCode bool
+}
+
+func (g *Gedis)FunctionList(opt *FunctionListOptions) ([]Library, error) {
+	args := make([]interface{}, 0, 4)
+	args = append(args, "LIST")
+	if opt != nil {
+		if opt.Pattern != "" {
+			args = append(args, "LIBRARYNAME", opt.Pattern)
+		}
+		if opt.WithCode {
+			args = append(args, "WITHCODE")
+		}
+	}
+	r := g.Cmd("FUNCTION", args...)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	libs := make([]Library, len(r.Children))
+	for i, c := range r.Children {
+		m, err := kvReplies(c)
+		if err != nil {
+			return nil, err
+		}
+		libs[i] = Library{
+			Name: kvStr(m, "library_name"),
+			Engine: kvStr(m, "engine"),
+			Code: kvStr(m, "library_code"),
+		}
+		if fs, ok := m["functions"]; ok {
+			for _, f := range fs.Children {
+				fm, err := kvReplies(f)
+				if err != nil {
+					return nil, err
+				}
+				fn := LibraryFunction{
+					Name: kvStr(fm, "name"),
+					Description: kvStr(fm, "description"),
+				}
+				if flags, ok := fm["flags"]; ok && flags.Type == MultiReply {
+					if fn.Flags, err = flags.List(); err != nil {
+						return nil, err
+					}
+				}
+				libs[i].Functions = append(libs[i].Functions, fn)
+			}
+		}
+	}
+	return libs, nil
+}
+
+// 删除函数库，成功后返回"OK"
+func (g *Gedis)FunctionDelete(library string) (string, error) {
+	return g.Cmd("FUNCTION", "DELETE", library).Str()
+}
+
+// 删除所有函数库，async为true时异步删除
+func (g *Gedis)FunctionFlush(async bool) (string, error) {
+	if async {
+		return g.Cmd("FUNCTION", "FLUSH", "ASYNC").Str()
+	}
+	return g.Cmd("FUNCTION", "FLUSH", "SYNC").Str()
+}
+
+// 返回所有函数库序列化后的值，可用于FunctionRestore
+func (g *Gedis)FunctionDump() ([]byte, error) {
+	return g.Cmd("FUNCTION", "DUMP").Bytes()
+}
+
+// FUNCTION RESTORE遇到已存在的函数库时的处理方式
+type FunctionRestorePolicy string
+
+const (
+	FunctionRestoreAppend  FunctionRestorePolicy = "APPEND"  // 同名库已存在时报错(默认)
+	FunctionRestoreReplace FunctionRestorePolicy = "REPLACE" // 覆盖同名库
+	FunctionRestoreFlush   FunctionRestorePolicy = "FLUSH"   // 先删除所有已存在的库
+)
+
+func (g *Gedis)FunctionRestore(payload []byte, policy FunctionRestorePolicy) (string, error) {
+	if policy == "" {
+		return g.Cmd("FUNCTION", "RESTORE", payload).Str()
+	}
+	return g.Cmd("FUNCTION", "RESTORE", payload, string(policy)).Str()
+}
+
+// 终止正在执行且未执行过写操作的函数
+func (g *Gedis)FunctionKill() (string, error) {
+	return g.Cmd("FUNCTION", "KILL").Str()
+}
+
+// FUNCTION STATS的返回值
+type FunctionStats struct {
+	// 当前正在执行的函数，没有时为nil
+	RunningScript *RunningScript
+	// 每种引擎(如LUA)加载的库和函数数量
+	Engines       map[string]EngineStats
+}
+
+type RunningScript struct {
+	Name     string
+	Command  []string
+	Duration time.Duration
+}
+
+type EngineStats struct {
+	LibrariesCount int64
+	FunctionsCount int64
+}
+
+func (g *Gedis)FunctionStats() (*FunctionStats, error) {
+	m, err := kvReplies(g.Cmd("FUNCTION", "STATS"))
+	if err != nil {
+		return nil, err
+	}
+	stats := &FunctionStats{Engines: make(map[string]EngineStats)}
+	if rs, ok := m["running_script"]; ok && rs.Type == MultiReply {
+		sm, err := kvReplies(rs)
+		if err != nil {
+			return nil, err
+		}
+		stats.RunningScript = &RunningScript{
+			Name: kvStr(sm, "name"),
+			Duration: time.Duration(kvInt(sm, "duration_ms")) * time.Millisecond,
+		}
+		if cmd, ok := sm["command"]; ok && cmd.Type == MultiReply {
+			if stats.RunningScript.Command, err = cmd.List(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if es, ok := m["engines"]; ok && es.Type == MultiReply {
+		em, err := kvReplies(es)
+		if err != nil {
+			return nil, err
+		}
+		for name, e := range em {
+			m, err := kvReplies(e)
+			if err != nil {
+				return nil, err
+			}
+			stats.Engines[name] = EngineStats{
+				LibrariesCount: kvInt(m, "libraries_count"),
+				FunctionsCount: kvInt(m, "functions_count"),
+			}
+		}
+	}
+	return stats, nil
+}
+
+func (g *Gedis)FCall(function string, keys []string, args... interface{}) *Reply {
+	return g.Cmd("FCALL", function, len(keys), keys, args)
+}
+
+// 只读版本的FCALL，只能调用带有no-writes标记的函数，可以在只读副本上执行
+func (g *Gedis)FCallRO(function string, keys []string, args... interface{}) *Reply {
+	return g.Cmd("FCALL_RO", function, len(keys), keys, args)
+}
+
+// 部署时写入库代码的版本标记，紧跟在 #!lua name=<library> 之后
+const libraryVersionMarker = "-- gedis:version="
+
+// 带版本号的函数库，用于在多个节点上检查并升级同一个库:
+//
+//	lib := &FunctionLibrary{Name: "inventory", Version: "3", Code: code}
+//	err := lib.DeploySharded(shardedGedis)
+//
+// 版本号以注释的形式写入加载到服务端的代码中，部署时通过FUNCTION LIST WITHCODE读取已加载的版本，
+// 与Version不一致(或库不存在)时使用FUNCTION LOAD REPLACE升级
+type FunctionLibrary struct {
+	Name    string
+	// 不能为空，代码变化时需要同时修改
+	Version string
+	// 以 #!lua name=<Name> 开头的库代码
+	Code    string
+}
+
+// 版本号为空时无法区分没有版本标记的旧代码，Deploy返回该错误
+var ErrEmptyLibraryVersion = errors.New("gedis: function library version is empty")
+
+// 在g所连接的节点上部署，返回是否执行了加载/升级；Version不能为空
+func (l *FunctionLibrary)Deploy(g *Gedis) (bool, error) {
+	if l.Version == "" {
+		return false, ErrEmptyLibraryVersion
+	}
+	libs, err := g.FunctionList(&FunctionListOptions{Pattern: l.Name, WithCode: true})
+	if err != nil {
+		return false, err
+	}
+	for _, lib := range libs {
+		if lib.Name == l.Name && libraryVersion(lib.Code) == l.Version {
+			return false, nil
+		}
+	}
+	if _, err = g.FunctionLoad(l.versionedCode(), true); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// 在连接池所连接的节点上部署
+func (l *FunctionLibrary)DeployPool(p *GedisPool) (bool, error) {
+	g, err := p.Get()
+	if err != nil {
+		return false, err
+	}
+	defer g.Close()
+	return l.Deploy(g)
+}
+
+// 在每一个分片上部署，返回执行了加载/升级的分片数
+func (l *FunctionLibrary)DeploySharded(s *ShardedGedis) (int, error) {
+	upgraded := 0
+	for _, g := range s.AllShards() {
+		ok, err := l.Deploy(g)
+		if err != nil {
+			return upgraded, err
+		}
+		if ok {
+			upgraded++
+		}
+	}
+	return upgraded, nil
+}
+
+// 在shebang行之后插入版本标记
+func (l *FunctionLibrary)versionedCode() string {
+	marker := libraryVersionMarker + l.Version
+	i := strings.IndexByte(l.Code, '\n')
+	if i < 0 {
+		return l.Code + "\n" + marker + "\n"
+	}
+	return l.Code[:i + 1] + marker + "\n" + l.Code[i + 1:]
+}
+
+// 从已加载的库代码中读取版本标记，没有标记时返回""
+func libraryVersion(code string) string {
+	for _, line := range strings.Split(code, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, libraryVersionMarker) {
+			return strings.TrimPrefix(line, libraryVersionMarker)
+		}
+	}
+	return ""
+}
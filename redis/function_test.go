@@ -0,0 +1,71 @@
+package gedis
+
+import (
+	"strings"
+	"testing"
+)
+
+const testLibCode = "#!lua name=inventory\nredis.register_function('stock', function() return 1 end)\n"
+
+func listReply(name, code string) string {
+	return respArray(respArray(
+		respBulk("library_name"), respBulk(name),
+		respBulk("engine"), respBulk("LUA"),
+		respBulk("functions"), respArray(),
+		respBulk("library_code"), respBulk(code),
+	))
+}
+
+func TestLibraryVersion(t *testing.T) {
+	l := &FunctionLibrary{Name: "inventory", Version: "3", Code: testLibCode}
+	code := l.versionedCode()
+	if !strings.HasPrefix(code, "#!lua name=inventory\n"+libraryVersionMarker+"3\n") {
+		t.Fatalf("versionedCode = %q", code)
+	}
+	if v := libraryVersion(code); v != "3" {
+		t.Fatalf("libraryVersion = %q", v)
+	}
+	if v := libraryVersion(testLibCode); v != "" {
+		t.Fatalf("libraryVersion(unversioned) = %q", v)
+	}
+	oneLine := &FunctionLibrary{Version: "1", Code: "#!lua name=x"}
+	if v := libraryVersion(oneLine.versionedCode()); v != "1" {
+		t.Fatalf("libraryVersion(one line) = %q", v)
+	}
+}
+
+func TestFunctionLibraryDeploy(t *testing.T) {
+	l := &FunctionLibrary{Name: "inventory", Version: "3", Code: testLibCode}
+	tests := []struct {
+		name   string
+		list   string
+		loaded bool
+	}{
+		{"missing", respArray(), true},
+		{"same version", listReply("inventory", l.versionedCode()), false},
+		{"old version", listReply("inventory", (&FunctionLibrary{Version: "2", Code: testLibCode}).versionedCode()), true},
+		{"no marker", listReply("inventory", testLibCode), true},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis(tt.list, respBulk("inventory"))
+		loaded, err := l.Deploy(g)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if loaded != tt.loaded {
+			t.Errorf("%s: loaded = %v, want %v", tt.name, loaded, tt.loaded)
+		}
+		if tt.loaded && !strings.Contains(fc.written.String(), "REPLACE") {
+			t.Errorf("%s: FUNCTION LOAD REPLACE not sent: %q", tt.name, fc.written.String())
+		}
+	}
+
+	g, fc := newFakeGedis(listReply("inventory", testLibCode))
+	if _, err := (&FunctionLibrary{Name: "inventory", Code: testLibCode}).Deploy(g); err != ErrEmptyLibraryVersion {
+		t.Fatalf("empty version err = %v", err)
+	}
+	if fc.written.Len() != 0 {
+		t.Fatalf("commands sent with empty version: %q", fc.written.String())
+	}
+}
@@ -1,6 +1,9 @@
 package gedis
 
-import "strconv"
+import (
+	"strconv"
+	"time"
+)
 
 type Gedis struct {
 	conn *Connection
@@ -8,6 +11,12 @@ type Gedis struct {
 	// 如果Gedis对象是从pool中获取，则设置pool属性
 	// 用于在close是判断是真的关闭连接，还是还给pool
 	Pool Pool
+
+	// 为true时命令只添加到连接的待发送队列而不执行，参见Pipeline、Tx
+	queued bool
+
+	// CLIENT REPLY OFF/SKIP之后为true，直到CLIENT REPLY ON，参见ClientReply
+	replyOff bool
 }
 
 func NewGedis(host string, port int) (*Gedis, error) {
@@ -23,9 +32,17 @@ func NewGedis(host string, port int) (*Gedis, error) {
 
 func (g *Gedis)Close() {
 	if g.Pool != nil {
+		// 丢弃没有Exec的管道命令，避免被下一个使用者的Exec发送
+		g.conn.Discard()
+		// 还回连接池前恢复CLIENT REPLY ON，否则下一个使用者会一直等不到返回值；恢复失败时关闭连接
+		if g.replyOff && g.ClientReply(ClientReplyOn) != nil {
+			g.conn.Close()
+		}
 		g.Pool.Put(g)
 	}else {
-		g.Quit()
+		if !g.conn.isClosed() {
+			g.Quit()
+		}
 		g.conn.Close()
 	}
 }
@@ -34,18 +51,41 @@ func (g *Gedis)ReadReply() *Reply {
 	return g.conn.ReadReply()
 }
 
+// 读取阻塞命令的返回值，参见Connection.ReadReplyBlocking
+func (g *Gedis)ReadReplyBlocking(block time.Duration) *Reply {
+	return g.conn.ReadReplyBlocking(block)
+}
+
+// 开启/关闭Reply复用模式，参见Connection.SetReplyPool
+func (g *Gedis)SetReplyPool(enabled bool) {
+	g.conn.SetReplyPool(enabled)
+}
+
 func (g *Gedis)Cmd(cmd string, args...interface{}) *Reply {
+	if g.queued {
+		g.conn.Append(cmd, args...)
+		return queuedReply
+	}
 	return g.conn.Exec(cmd, args...)
 }
+// 执行阻塞命令，参见Connection.ExecBlocking
+func (g *Gedis)BlockingCmd(block time.Duration, cmd string, args...interface{}) *Reply {
+	if g.queued {
+		g.conn.Append(cmd, args...)
+		return queuedReply
+	}
+	return g.conn.ExecBlocking(block, cmd, args...)
+}
+
 // Gedis提供基本的Redis操作命令 TODO 后续不断完善
 
 // set成功后返回"OK"
 func (g *Gedis)Set(key string, value interface{}) (string, error) {
-	return g.Cmd("SET", value).Str()
+	return g.Cmd("SET", key, value).Str()
 }
 
 func (g *Gedis)Get(key string) *Reply {
-	return g.Cmd("GET")
+	return g.Cmd("GET", key)
 }
 
 func (g *Gedis)Del(keys... string) (int, error) {
@@ -68,8 +108,9 @@ func (g *Gedis)Save() (string, error) {
 	return g.Cmd("SAVE").Str()
 }
 
+// 参见ShutdownWithOptions
 func (g *Gedis)Shutdown() error {
-	return g.Cmd("SHUTDOWN").Nil()
+	return g.ShutdownWithOptions(nil)
 }
 
 func (g *Gedis)Quit() {
@@ -77,7 +118,7 @@ func (g *Gedis)Quit() {
 }
 
 func (g *Gedis)Subscribe(channels... string) {
-	g.Cmd("SUBSCRIBE", channels...)
+	g.Cmd("SUBSCRIBE", channels)
 }
 
 func (g *Gedis)Sentinel(args...interface{}) ([]string, error) {
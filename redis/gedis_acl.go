@@ -0,0 +1,378 @@
+package gedis
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ACL相关的命令
+
+// ACL SETUSER的规则构造器，规则按添加的顺序发送:
+//
+//	rules := NewACLRules().On().Password("secret").Keys("order:*").
+//		Channels("order-events").AllowCategories("read", "write").DenyCommands("flushall")
+//	_, err := gedis.ACLSetUser("order-service", rules)
+type ACLRules struct {
+	rules []string
+}
+
+func NewACLRules() *ACLRules {
+	return &ACLRules{}
+}
+
+// 添加原始规则，用于构造器没有覆盖到的规则
+func (a *ACLRules)Rule(rules... string) *ACLRules {
+	a.rules = append(a.rules, rules...)
+	return a
+}
+
+// 先重置用户的所有规则(reset)，使之后的规则成为用户的完整定义
+func (a *ACLRules)Reset() *ACLRules {
+	return a.Rule("reset")
+}
+
+func (a *ACLRules)On() *ACLRules {
+	return a.Rule("on")
+}
+
+func (a *ACLRules)Off() *ACLRules {
+	return a.Rule("off")
+}
+
+// 添加明文密码(>password)
+func (a *ACLRules)Password(passwords... string) *ACLRules {
+	for _, p := range passwords {
+		a.rules = append(a.rules, ">" + p)
+	}
+	return a
+}
+
+// 添加密码的SHA256(#hash)，避免在部署配置中出现明文
+func (a *ACLRules)PasswordHash(hashes... string) *ACLRules {
+	for _, h := range hashes {
+		a.rules = append(a.rules, "#" + h)
+	}
+	return a
+}
+
+// 移除密码(<password)
+func (a *ACLRules)RemovePassword(passwords... string) *ACLRules {
+	for _, p := range passwords {
+		a.rules = append(a.rules, "<" + p)
+	}
+	return a
+}
+
+// 不需要密码即可登录
+func (a *ACLRules)NoPass() *ACLRules {
+	return a.Rule("nopass")
+}
+
+// 清除所有密码
+func (a *ACLRules)ResetPass() *ACLRules {
+	return a.Rule("resetpass")
+}
+
+// 允许读写匹配patterns的key(~pattern)
+func (a *ACLRules)Keys(patterns... string) *ACLRules {
+	for _, p := range patterns {
+		a.rules = append(a.rules, "~" + p)
+	}
+	return a
+}
+
+// 只允许读匹配patterns的key(%R~pattern)
+func (a *ACLRules)ReadKeys(patterns... string) *ACLRules {
+	for _, p := range patterns {
+		a.rules = append(a.rules, "%R~" + p)
+	}
+	return a
+}
+
+// 只允许写匹配patterns的key(%W~pattern)
+func (a *ACLRules)WriteKeys(patterns... string) *ACLRules {
+	for _, p := range patterns {
+		a.rules = append(a.rules, "%W~" + p)
+	}
+	return a
+}
+
+func (a *ACLRules)AllKeys() *ACLRules {
+	return a.Rule("allkeys")
+}
+
+func (a *ACLRules)ResetKeys() *ACLRules {
+	return a.Rule("resetkeys")
+}
+
+// 允许访问匹配patterns的Pub/Sub频道(&pattern)
+func (a *ACLRules)Channels(patterns... string) *ACLRules {
+	for _, p := range patterns {
+		a.rules = append(a.rules, "&" + p)
+	}
+	return a
+}
+
+func (a *ACLRules)AllChannels() *ACLRules {
+	return a.Rule("allchannels")
+}
+
+func (a *ACLRules)ResetChannels() *ACLRules {
+	return a.Rule("resetchannels")
+}
+
+// 允许执行命令(+command，子命令使用 command|subcommand)
+func (a *ACLRules)AllowCommands(commands... string) *ACLRules {
+	for _, c := range commands {
+		a.rules = append(a.rules, "+" + c)
+	}
+	return a
+}
+
+// 禁止执行命令(-command)
+func (a *ACLRules)DenyCommands(commands... string) *ACLRules {
+	for _, c := range commands {
+		a.rules = append(a.rules, "-" + c)
+	}
+	return a
+}
+
+// 允许执行分类中的命令(+@category)，分类可通过ACLCat获取
+func (a *ACLRules)AllowCategories(categories... string) *ACLRules {
+	for _, c := range categories {
+		a.rules = append(a.rules, "+@" + c)
+	}
+	return a
+}
+
+// 禁止执行分类中的命令(-@category)
+func (a *ACLRules)DenyCategories(categories... string) *ACLRules {
+	for _, c := range categories {
+		a.rules = append(a.rules, "-@" + c)
+	}
+	return a
+}
+
+func (a *ACLRules)AllCommands() *ACLRules {
+	return a.Rule("allcommands")
+}
+
+func (a *ACLRules)NoCommands() *ACLRules {
+	return a.Rule("nocommands")
+}
+
+// 添加一个选择器((rule rule ...))，选择器内的规则作为一个独立的权限集合生效(Redis 7.0+)
+func (a *ACLRules)Selector(selector *ACLRules) *ACLRules {
+	return a.Rule("(" + strings.Join(selector.rules, " ") + ")")
+}
+
+func (a *ACLRules)ClearSelectors() *ACLRules {
+	return a.Rule("clearselectors")
+}
+
+func (a *ACLRules)args() []interface{} {
+	args := make([]interface{}, len(a.rules))
+	for i, r := range a.rules {
+		args[i] = r
+	}
+	return args
+}
+
+// 返回密码的SHA256，可用于ACLRules.PasswordHash
+func ACLPasswordHash(password string) string {
+	h := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(h[:])
+}
+
+// 创建或修改用户，成功后返回"OK"
+func (g *Gedis)ACLSetUser(username string, rules *ACLRules) (string, error) {
+	if rules == nil {
+		return g.Cmd("ACL", "SETUSER", username).Str()
+	}
+	return g.Cmd("ACL", "SETUSER", username, rules.args()).Str()
+}
+
+// ACL GETUSER的返回值
+type ACLUser struct {
+	Flags     []string
+	// 密码的SHA256
+	Passwords []string
+	Commands  string
+	Keys      string
+	Channels  string
+	Selectors []ACLSelector
+}
+
+// 用户的一个选择器
+type ACLSelector struct {
+	Commands string
+	Keys     string
+	Channels string
+}
+
+// 用户不存在时返回ErrNil
+func (g *Gedis)ACLGetUser(username string) (*ACLUser, error) {
+	return parseACLUser(g.Cmd("ACL", "GETUSER", username))
+}
+
+func parseACLUser(r *Reply) (*ACLUser, error) {
+	if r.Type == NilReply {
+		return nil, ErrNil
+	}
+	m, err := kvReplies(r)
+	if err != nil {
+		return nil, err
+	}
+	u := &ACLUser{
+		Commands: kvStr(m, "commands"),
+		Keys: kvStr(m, "keys"),
+		Channels: kvStr(m, "channels"),
+	}
+	if flags, ok := m["flags"]; ok {
+		if u.Flags, err = flags.List(); err != nil {
+			return nil, err
+		}
+	}
+	if passwords, ok := m["passwords"]; ok {
+		if u.Passwords, err = passwords.List(); err != nil {
+			return nil, err
+		}
+	}
+	if selectors, ok := m["selectors"]; ok {
+		for _, s := range selectors.Children {
+			sm, err := kvReplies(s)
+			if err != nil {
+				return nil, err
+			}
+			u.Selectors = append(u.Selectors, ACLSelector{
+				Commands: kvStr(sm, "commands"),
+				Keys: kvStr(sm, "keys"),
+				Channels: kvStr(sm, "channels"),
+			})
+		}
+	}
+	return u, nil
+}
+
+// 以ACL配置文件的格式返回所有用户的规则
+func (g *Gedis)ACLList() ([]string, error) {
+	return g.Cmd("ACL", "LIST").List()
+}
+
+func (g *Gedis)ACLUsers() ([]string, error) {
+	return g.Cmd("ACL", "USERS").List()
+}
+
+// 删除用户，返回删除的数量
+func (g *Gedis)ACLDelUser(usernames... string) (int64, error) {
+	return g.Cmd("ACL", "DELUSER", usernames).Int64()
+}
+
+func (g *Gedis)ACLWhoAmI() (string, error) {
+	return g.Cmd("ACL", "WHOAMI").Str()
+}
+
+// 返回所有命令分类，category不为空时返回该分类下的命令
+func (g *Gedis)ACLCat(category string) ([]string, error) {
+	if category == "" {
+		return g.Cmd("ACL", "CAT").List()
+	}
+	return g.Cmd("ACL", "CAT", category).List()
+}
+
+// 生成随机密码，bits为0时使用默认的256位
+func (g *Gedis)ACLGenPass(bits int) (string, error) {
+	if bits > 0 {
+		return g.Cmd("ACL", "GENPASS", bits).Str()
+	}
+	return g.Cmd("ACL", "GENPASS").Str()
+}
+
+// ACL LOG中的一条安全事件
+type ACLLogEntry struct {
+	Count                 int64
+	// command、key、channel或auth
+	Reason                string
+	// toplevel、multi、lua或module
+	Context               string
+	Object                string
+	Username              string
+	AgeSeconds            float64
+	ClientInfo            ClientInfo
+	EntryID               int64
+	TimestampCreated      time.Time
+	TimestampLastUpdated  time.Time
+}
+
+// 返回最近count条安全事件，count为0时使用服务端默认值(10)
+func (g *Gedis)ACLLog(count int64) ([]ACLLogEntry, error) {
+	var r *Reply
+	if count > 0 {
+		r = g.Cmd("ACL", "LOG", count)
+	} else {
+		r = g.Cmd("ACL", "LOG")
+	}
+	return parseACLLog(r)
+}
+
+func parseACLLog(r *Reply) ([]ACLLogEntry, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	entries := make([]ACLLogEntry, len(r.Children))
+	for i, c := range r.Children {
+		m, err := kvReplies(c)
+		if err != nil {
+			return nil, err
+		}
+		entries[i] = ACLLogEntry{
+			Count: kvInt(m, "count"),
+			Reason: kvStr(m, "reason"),
+			Context: kvStr(m, "context"),
+			Object: kvStr(m, "object"),
+			Username: kvStr(m, "username"),
+			AgeSeconds: kvFloat(m, "age-seconds"),
+			ClientInfo: ParseClientInfo(kvStr(m, "client-info")),
+			EntryID: kvInt(m, "entry-id"),
+			TimestampCreated: time.UnixMilli(kvInt(m, "timestamp-created")),
+			TimestampLastUpdated: time.UnixMilli(kvInt(m, "timestamp-last-updated")),
+		}
+	}
+	return entries, nil
+}
+
+// 清空安全事件日志
+func (g *Gedis)ACLLogReset() (string, error) {
+	return g.Cmd("ACL", "LOG", "RESET").Str()
+}
+
+// 模拟用户执行命令，有权限时返回nil，否则返回服务端给出的原因
+func (g *Gedis)ACLDryRun(username, command string, args... interface{}) error {
+	s, err := g.Cmd("ACL", "DRYRUN", username, command, args).Str()
+	if err != nil {
+		return err
+	}
+	if s != "OK" {
+		return errors.New(s)
+	}
+	return nil
+}
+
+// 将当前的ACL规则保存到ACL文件
+func (g *Gedis)ACLSave() (string, error) {
+	return g.Cmd("ACL", "SAVE").Str()
+}
+
+// 从ACL文件重新加载规则
+func (g *Gedis)ACLLoad() (string, error) {
+	return g.Cmd("ACL", "LOAD").Str()
+}
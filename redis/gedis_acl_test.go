@@ -0,0 +1,97 @@
+package gedis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestACLRules(t *testing.T) {
+	rules := NewACLRules().Reset().On().Password("a").PasswordHash("h").RemovePassword("b").
+		Keys("k:*").ReadKeys("r:*").WriteKeys("w:*").Channels("c").
+		AllowCommands("get").DenyCommands("flushall").AllowCategories("read").DenyCategories("dangerous").
+		Selector(NewACLRules().Keys("s:*").AllowCommands("set")).
+		Selector(NewACLRules()).
+		Rule("resetchannels")
+	want := "[reset on >a #h <b ~k:* %R~r:* %W~w:* &c +get -flushall +@read -@dangerous (~s:* +set) () resetchannels]"
+	if got := fmt.Sprint(rules.args()); got != want {
+		t.Errorf("args = %s, want %s", got, want)
+	}
+
+	g, fc := newFakeGedis("+OK\r\n")
+	if _, err := g.ACLSetUser("u", NewACLRules().On().Selector(NewACLRules().AllKeys().AllCommands())); err != nil {
+		t.Fatalf("ACLSetUser: %v", err)
+	}
+	if w := respCommand("ACL", "SETUSER", "u", "on", "(allkeys allcommands)"); fc.written.String() != w {
+		t.Errorf("written = %q, want %q", fc.written.String(), w)
+	}
+}
+
+func TestParseACLUser(t *testing.T) {
+	r := mustReply(t, respArray(
+		respBulk("flags"), respArray(respBulk("on"), respBulk("sanitize-payload")),
+		respBulk("passwords"), respArray(respBulk("2bb80d53")),
+		respBulk("commands"), respBulk("+@all"),
+		respBulk("keys"), respBulk("~k:*"),
+		respBulk("channels"), respBulk(""),
+		respBulk("selectors"), respArray(
+			respArray(respBulk("commands"), respBulk("-@all +get"), respBulk("keys"), respBulk("~s:*"), respBulk("channels"), respBulk("")),
+		),
+	))
+	u, err := parseACLUser(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ACLUser{
+		Flags:     []string{"on", "sanitize-payload"},
+		Passwords: []string{"2bb80d53"},
+		Commands:  "+@all",
+		Keys:      "~k:*",
+		Selectors: []ACLSelector{{Commands: "-@all +get", Keys: "~s:*"}},
+	}
+	if !reflect.DeepEqual(u, want) {
+		t.Errorf("parseACLUser = %+v, want %+v", u, want)
+	}
+
+	if _, err := parseACLUser(mustReply(t, "*-1\r\n")); err != ErrNil {
+		t.Errorf("missing user err = %v, want ErrNil", err)
+	}
+}
+
+func TestParseACLLog(t *testing.T) {
+	r := mustReply(t, respArray(respArray(
+		respBulk("count"), ":2\r\n",
+		respBulk("reason"), respBulk("command"),
+		respBulk("context"), respBulk("toplevel"),
+		respBulk("object"), respBulk("get"),
+		respBulk("username"), respBulk("u"),
+		respBulk("age-seconds"), respBulk("4.5"),
+		respBulk("client-info"), respBulk("id=3 addr=127.0.0.1:52555 name=worker"),
+		respBulk("entry-id"), ":7\r\n",
+		respBulk("timestamp-created"), ":1700000000000\r\n",
+		respBulk("timestamp-last-updated"), ":1700000001500\r\n",
+	)))
+	entries, err := parseACLLog(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("len = %d, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Count != 2 || e.Reason != "command" || e.Context != "toplevel" || e.Object != "get" || e.Username != "u" ||
+		e.AgeSeconds != 4.5 || e.EntryID != 7 {
+		t.Errorf("entry = %+v", e)
+	}
+	if e.ClientInfo.ID != 3 || e.ClientInfo.Name != "worker" {
+		t.Errorf("ClientInfo = %+v", e.ClientInfo)
+	}
+	if !e.TimestampCreated.Equal(time.UnixMilli(1700000000000)) || !e.TimestampLastUpdated.Equal(time.UnixMilli(1700000001500)) {
+		t.Errorf("timestamps = %v, %v", e.TimestampCreated, e.TimestampLastUpdated)
+	}
+
+	if _, err := parseACLLog(mustReply(t, respArray(respArray(respBulk("count"))))); err == nil {
+		t.Error("malformed entry was accepted")
+	}
+}
@@ -0,0 +1,196 @@
+package gedis
+
+import (
+	"errors"
+	"strconv"
+)
+
+// HyperLogLog、Bitmap及BITFIELD相关的命令
+
+// 返回HyperLogLog的内部寄存器是否被修改
+func (g *Gedis)PFAdd(key string, elements... interface{}) (bool, error) {
+	return g.Cmd("PFADD", key, elements).Bool()
+}
+
+// 返回基数估算值，多个key时返回并集的基数
+func (g *Gedis)PFCount(keys... string) (int64, error) {
+	return g.Cmd("PFCOUNT", keys).Int64()
+}
+
+// 合并到destination，成功后返回"OK"
+func (g *Gedis)PFMerge(destination string, keys... string) (string, error) {
+	return g.Cmd("PFMERGE", destination, keys).Str()
+}
+
+// 设置offset处的bit，返回原来的值
+func (g *Gedis)SetBit(key string, offset int64, value int) (int64, error) {
+	return g.Cmd("SETBIT", key, offset, value).Int64()
+}
+
+func (g *Gedis)GetBit(key string, offset int64) (int64, error) {
+	return g.Cmd("GETBIT", key, offset).Int64()
+}
+
+// BITCOUNT/BITPOS的区间单位，为空时使用服务端默认的BYTE
+type BitRangeUnit string
+
+const (
+	BitRangeByte BitRangeUnit = "BYTE"
+	BitRangeBit  BitRangeUnit = "BIT"
+)
+
+// BITCOUNT/BITPOS的区间；
+// OmitEnd为true时只发送Start(BITPOS key bit start)，此时不能指定Unit，BITCOUNT不支持这种形式
+type BitRange struct {
+	Start   int64
+	End     int64
+	OmitEnd bool
+	Unit    BitRangeUnit
+}
+
+func (b *BitRange) args() []interface{} {
+	if b == nil {
+		return nil
+	}
+	if b.OmitEnd {
+		return []interface{}{b.Start}
+	}
+	if b.Unit != "" {
+		return []interface{}{b.Start, b.End, string(b.Unit)}
+	}
+	return []interface{}{b.Start, b.End}
+}
+
+// 统计值为1的bit数，r为nil时统计整个字符串
+func (g *Gedis)BitCount(key string, r *BitRange) (int64, error) {
+	return g.Cmd("BITCOUNT", key, r.args()).Int64()
+}
+
+// 返回第一个值为bit的位置，r为nil时查找整个字符串
+func (g *Gedis)BitPos(key string, bit int, r *BitRange) (int64, error) {
+	return g.Cmd("BITPOS", key, bit, r.args()).Int64()
+}
+
+type BitOperation string
+
+const (
+	BitAnd BitOperation = "AND"
+	BitOr  BitOperation = "OR"
+	BitXor BitOperation = "XOR"
+	BitNot BitOperation = "NOT"
+)
+
+// 对keys做位运算并保存到destination，返回结果的长度
+func (g *Gedis)BitOp(op BitOperation, destination string, keys... string) (int64, error) {
+	return g.Cmd("BITOP", string(op), destination, keys).Int64()
+}
+
+// BITFIELD中的整数类型，如i8、u16
+type BitFieldType string
+
+// 有符号整数，bits最大为64
+func Signed(bits int) BitFieldType {
+	return BitFieldType("i" + strconv.Itoa(bits))
+}
+
+// 无符号整数，bits最大为63
+func Unsigned(bits int) BitFieldType {
+	return BitFieldType("u" + strconv.Itoa(bits))
+}
+
+// 以bit为单位的偏移量
+func BitOffset(n int64) string {
+	return strconv.FormatInt(n, 10)
+}
+
+// 以类型宽度为单位的偏移量，即 #n，表示第n个该类型的整数
+func TypeOffset(n int64) string {
+	return "#" + strconv.FormatInt(n, 10)
+}
+
+// BITFIELD中SET/INCRBY溢出时的处理方式
+type BitFieldOverflow string
+
+const (
+	OverflowWrap BitFieldOverflow = "WRAP" // 回绕(默认)
+	OverflowSat  BitFieldOverflow = "SAT"  // 饱和到最大/最小值
+	OverflowFail BitFieldOverflow = "FAIL" // 不执行，对应的返回值为nil
+)
+
+// BITFIELD/BITFIELD_RO的构造器:
+//
+//	bf := NewBitField("counters").
+//		Overflow(OverflowSat).IncrBy(Unsigned(8), TypeOffset(3), 1).
+//		Get(Unsigned(8), TypeOffset(4))
+//	values, err := gedis.BitField(bf)
+type BitFieldBuilder struct {
+	key  string
+	args []interface{}
+	// 是否只包含GET子命令，BITFIELD_RO只支持GET
+	readOnly bool
+}
+
+func NewBitField(key string) *BitFieldBuilder {
+	return &BitFieldBuilder{key: key, readOnly: true}
+}
+
+func (b *BitFieldBuilder)Get(t BitFieldType, offset string) *BitFieldBuilder {
+	b.args = append(b.args, "GET", string(t), offset)
+	return b
+}
+
+func (b *BitFieldBuilder)Set(t BitFieldType, offset string, value int64) *BitFieldBuilder {
+	b.args = append(b.args, "SET", string(t), offset, value)
+	b.readOnly = false
+	return b
+}
+
+func (b *BitFieldBuilder)IncrBy(t BitFieldType, offset string, increment int64) *BitFieldBuilder {
+	b.args = append(b.args, "INCRBY", string(t), offset, increment)
+	b.readOnly = false
+	return b
+}
+
+// 设置之后的SET/INCRBY的溢出处理方式，直到下一次调用Overflow
+func (b *BitFieldBuilder)Overflow(o BitFieldOverflow) *BitFieldBuilder {
+	b.args = append(b.args, "OVERFLOW", string(o))
+	b.readOnly = false
+	return b
+}
+
+// 返回值与GET/SET/INCRBY子命令一一对应，OVERFLOW FAIL时未执行的子命令对应nil
+func (g *Gedis)BitField(b *BitFieldBuilder) ([]*int64, error) {
+	return parseBitField(g.Cmd("BITFIELD", b.key, b.args))
+}
+
+// 只读版本的BITFIELD，只能包含GET子命令，可以在只读副本上执行
+func (g *Gedis)BitFieldRO(b *BitFieldBuilder) ([]*int64, error) {
+	if !b.readOnly {
+		return nil, errors.New("BITFIELD_RO only supports GET subcommands")
+	}
+	return parseBitField(g.Cmd("BITFIELD_RO", b.key, b.args))
+}
+
+func parseBitField(r *Reply) ([]*int64, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	values := make([]*int64, len(r.Children))
+	for i, c := range r.Children {
+		if c.Type == NilReply {
+			continue
+		}
+		n, err := c.Int64()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = &n
+	}
+	return values, nil
+}
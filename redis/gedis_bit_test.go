@@ -0,0 +1,73 @@
+package gedis
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseBitField(t *testing.T) {
+	values, err := parseBitField(mustReply(t, respArray(":1\r\n", "$-1\r\n", ":-128\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 3 || values[0] == nil || *values[0] != 1 || values[1] != nil || values[2] == nil || *values[2] != -128 {
+		t.Fatalf("parseBitField = %v", values)
+	}
+
+	empty, err := parseBitField(mustReply(t, respArray()))
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("parseBitField(empty) = %v, %v", empty, err)
+	}
+	for _, bad := range []string{"-ERR bad\r\n", ":1\r\n", respArray(respBulk("x"))} {
+		if _, err := parseBitField(mustReply(t, bad)); err == nil {
+			t.Errorf("parseBitField(%q) should fail", bad)
+		}
+	}
+}
+
+func TestBitFieldBuilder(t *testing.T) {
+	b := NewBitField("k").Overflow(OverflowSat).IncrBy(Unsigned(8), TypeOffset(3), 1).Get(Signed(16), BitOffset(100))
+	if got := fmt.Sprint(b.args); got != "[OVERFLOW SAT INCRBY u8 #3 1 GET i16 100]" {
+		t.Fatalf("args = %s", got)
+	}
+	if b.readOnly {
+		t.Fatal("builder with INCRBY is read only")
+	}
+	if !NewBitField("k").Get(Unsigned(4), BitOffset(0)).readOnly {
+		t.Fatal("GET-only builder is not read only")
+	}
+
+	g, fc := newFakeGedis()
+	if _, err := g.BitFieldRO(b); err == nil {
+		t.Fatal("BitFieldRO accepted a write subcommand")
+	}
+	if fc.written.Len() != 0 {
+		t.Fatalf("BITFIELD_RO was sent: %q", fc.written.String())
+	}
+}
+
+func TestBitRangeArgs(t *testing.T) {
+	tests := []struct {
+		r    *BitRange
+		want string
+	}{
+		{nil, "[]"},
+		{&BitRange{Start: 0, End: -1}, "[0 -1]"},
+		{&BitRange{Start: 1, End: 8, Unit: BitRangeBit}, "[1 8 BIT]"},
+		{&BitRange{Start: 2, OmitEnd: true}, "[2]"},
+		{&BitRange{Start: 2, OmitEnd: true, Unit: BitRangeBit}, "[2]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tt.r.args()); got != tt.want {
+			t.Errorf("%+v.args() = %s, want %s", tt.r, got, tt.want)
+		}
+	}
+
+	g, fc := newFakeGedis(":3\r\n")
+	if n, err := g.BitPos("k", 1, &BitRange{Start: 2, OmitEnd: true}); n != 3 || err != nil {
+		t.Fatalf("BitPos = %d, %v", n, err)
+	}
+	if w := respCommand("BITPOS", "k", "1", "2"); fc.written.String() != w {
+		t.Errorf("written = %q, want %q", fc.written.String(), w)
+	}
+}
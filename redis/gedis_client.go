@@ -0,0 +1,288 @@
+package gedis
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CLIENT相关的命令
+
+// CLIENT LIST/CLIENT INFO中的一个客户端连接，未知字段保存在Fields中
+type ClientInfo struct {
+	ID       int64
+	Addr     string
+	LAddr    string
+	FD       int64
+	Name     string
+	// 连接建立的时长
+	Age      time.Duration
+	// 空闲时长
+	Idle     time.Duration
+	Flags    string
+	DB       int
+	Sub      int64
+	PSub     int64
+	SSub     int64
+	Multi    int64
+	QBuf     int64
+	QBufFree int64
+	ArgvMem  int64
+	OBL      int64
+	OLL      int64
+	OMem     int64
+	// 连接占用的总内存
+	TotMem   int64
+	Events   string
+	// 最近执行的命令
+	Cmd      string
+	User     string
+	Redir    int64
+	Resp     int
+	LibName  string
+	LibVer   string
+
+	// 所有字段的原始值
+	Fields   map[string]string
+}
+
+// 解析CLIENT LIST返回的文本，每一行为一个客户端
+func ParseClientList(text string) []ClientInfo {
+	clients := make([]ClientInfo, 0)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		clients = append(clients, ParseClientInfo(line))
+	}
+	return clients
+}
+
+// 解析 id=1 addr=127.0.0.1:6379 ... 形式的一行，
+// 字段值中不会包含空格(name不允许包含空格)，但cmd等字段可能为空
+func ParseClientInfo(line string) ClientInfo {
+	fields := make(map[string]string)
+	for _, kv := range strings.Fields(line) {
+		if i := strings.IndexByte(kv, '='); i > 0 {
+			fields[kv[:i]] = kv[i + 1:]
+		}
+	}
+	i64 := func(k string) int64 {
+		n, _ := strconv.ParseInt(fields[k], 10, 64)
+		return n
+	}
+	return ClientInfo{
+		ID: i64("id"),
+		Addr: fields["addr"],
+		LAddr: fields["laddr"],
+		FD: i64("fd"),
+		Name: fields["name"],
+		Age: time.Duration(i64("age")) * time.Second,
+		Idle: time.Duration(i64("idle")) * time.Second,
+		Flags: fields["flags"],
+		DB: int(i64("db")),
+		Sub: i64("sub"),
+		PSub: i64("psub"),
+		SSub: i64("ssub"),
+		Multi: i64("multi"),
+		QBuf: i64("qbuf"),
+		QBufFree: i64("qbuf-free"),
+		ArgvMem: i64("argv-mem"),
+		OBL: i64("obl"),
+		OLL: i64("oll"),
+		OMem: i64("omem"),
+		TotMem: i64("tot-mem"),
+		Events: fields["events"],
+		Cmd: fields["cmd"],
+		User: fields["user"],
+		Redir: i64("redir"),
+		Resp: int(i64("resp")),
+		LibName: fields["lib-name"],
+		LibVer: fields["lib-ver"],
+		Fields: fields,
+	}
+}
+
+// 成功后返回"OK"
+func (g *Gedis)ClientSetName(name string) (string, error) {
+	return g.Cmd("CLIENT", "SETNAME", name).Str()
+}
+
+// 没有设置名称时返回ErrNil
+func (g *Gedis)ClientGetName() (string, error) {
+	return g.Cmd("CLIENT", "GETNAME").Str()
+}
+
+func (g *Gedis)ClientID() (int64, error) {
+	return g.Cmd("CLIENT", "ID").Int64()
+}
+
+// 返回当前连接的信息
+func (g *Gedis)ClientInfo() (*ClientInfo, error) {
+	text, err := g.Cmd("CLIENT", "INFO").Str()
+	if err != nil {
+		return nil, err
+	}
+	info := ParseClientInfo(strings.TrimSpace(text))
+	return &info, nil
+}
+
+// 设置客户端库的名称及版本，attr为"LIB-NAME"或"LIB-VER"
+func (g *Gedis)ClientSetInfo(attr, value string) (string, error) {
+	return g.Cmd("CLIENT", "SETINFO", attr, value).Str()
+}
+
+// CLIENT LIST的过滤条件，Type为normal、master、replica或pubsub
+type ClientListOptions struct {
+	Type string
+	IDs  []int64
+}
+
+func (g *Gedis)ClientList(opt *ClientListOptions) ([]ClientInfo, error) {
+	args := make([]interface{}, 0, 4)
+	args = append(args, "LIST")
+	if opt != nil {
+		if opt.Type != "" {
+			args = append(args, "TYPE", opt.Type)
+		}
+		if len(opt.IDs) > 0 {
+			args = append(args, "ID", opt.IDs)
+		}
+	}
+	text, err := g.Cmd("CLIENT", args...).Str()
+	if err != nil {
+		return nil, err
+	}
+	return ParseClientList(text), nil
+}
+
+// CLIENT KILL的过滤条件(新语法)，零值的条件不发送，多个条件之间为"与"的关系
+type ClientKillFilter struct {
+	ID     int64
+	Type   string
+	User   string
+	Addr   string
+	LAddr  string
+	// 为nil时使用服务端默认值(yes)，即不杀死当前连接
+	SkipMe *bool
+	// 只杀死建立时长大于MaxAge的连接(Redis 7.4+)
+	MaxAge time.Duration
+}
+
+func (f *ClientKillFilter) args() []interface{} {
+	args := make([]interface{}, 0, 12)
+	if f.ID > 0 {
+		args = append(args, "ID", f.ID)
+	}
+	if f.Type != "" {
+		args = append(args, "TYPE", f.Type)
+	}
+	if f.User != "" {
+		args = append(args, "USER", f.User)
+	}
+	if f.Addr != "" {
+		args = append(args, "ADDR", f.Addr)
+	}
+	if f.LAddr != "" {
+		args = append(args, "LADDR", f.LAddr)
+	}
+	if f.SkipMe != nil {
+		if *f.SkipMe {
+			args = append(args, "SKIPME", "yes")
+		} else {
+			args = append(args, "SKIPME", "no")
+		}
+	}
+	if f.MaxAge > 0 {
+		args = append(args, "MAXAGE", int64(f.MaxAge / time.Second))
+	}
+	return args
+}
+
+// 返回被杀死的连接数
+func (g *Gedis)ClientKill(filter *ClientKillFilter) (int64, error) {
+	return g.Cmd("CLIENT", "KILL", filter.args()).Int64()
+}
+
+// CLIENT PAUSE的模式，ALL暂停所有命令，WRITE只暂停写命令
+type ClientPauseMode string
+
+const (
+	ClientPauseAll   ClientPauseMode = "ALL"
+	ClientPauseWrite ClientPauseMode = "WRITE"
+)
+
+func (g *Gedis)ClientPause(timeout time.Duration, mode ClientPauseMode) (string, error) {
+	if mode == "" {
+		return g.Cmd("CLIENT", "PAUSE", int64(timeout / time.Millisecond)).Str()
+	}
+	return g.Cmd("CLIENT", "PAUSE", int64(timeout / time.Millisecond), string(mode)).Str()
+}
+
+func (g *Gedis)ClientUnpause() (string, error) {
+	return g.Cmd("CLIENT", "UNPAUSE").Str()
+}
+
+// 当前连接是否不被内存淘汰策略驱逐
+func (g *Gedis)ClientNoEvict(on bool) (string, error) {
+	return g.Cmd("CLIENT", "NO-EVICT", onOff(on)).Str()
+}
+
+// 当前连接执行的命令是否不更新key的LRU/LFU
+func (g *Gedis)ClientNoTouch(on bool) (string, error) {
+	return g.Cmd("CLIENT", "NO-TOUCH", onOff(on)).Str()
+}
+
+type ClientReplyMode string
+
+const (
+	ClientReplyOn   ClientReplyMode = "ON"
+	ClientReplyOff  ClientReplyMode = "OFF"
+	// 跳过下一条命令的返回值
+	ClientReplySkip ClientReplyMode = "SKIP"
+)
+
+// 设置服务端是否返回命令的结果；OFF/SKIP时服务端不会返回，所以只发送不读取，
+// 之后被跳过返回值的命令需要通过Send发送，否则会一直等待返回值直到读超时。
+// 从连接池获取的连接在Close时会自动恢复为ON；不能在管道或事务中调用，返回ErrNotQueueable
+func (g *Gedis)ClientReply(mode ClientReplyMode) error {
+	if g.queued {
+		return ErrNotQueueable
+	}
+	if mode == ClientReplyOn {
+		_, err := g.Cmd("CLIENT", "REPLY", string(mode)).Str()
+		if err == nil {
+			g.replyOff = false
+		}
+		return err
+	}
+	err := g.conn.Send("CLIENT", "REPLY", string(mode))
+	if err == nil {
+		g.replyOff = true
+	}
+	return err
+}
+
+// 发送命令但不读取返回值，参见ClientReply；不能在管道或事务中调用，返回ErrNotQueueable
+func (g *Gedis)Send(cmd string, args... interface{}) error {
+	if g.queued {
+		return ErrNotQueueable
+	}
+	return g.conn.Send(cmd, args...)
+}
+
+// 解除被阻塞的连接，withError为true时被阻塞的命令返回UNBLOCKED错误，否则如同超时；返回是否解除成功
+func (g *Gedis)ClientUnblock(id int64, withError bool) (bool, error) {
+	if withError {
+		return g.Cmd("CLIENT", "UNBLOCK", id, "ERROR").Bool()
+	}
+	return g.Cmd("CLIENT", "UNBLOCK", id).Bool()
+}
+
+func onOff(on bool) string {
+	if on {
+		return "ON"
+	}
+	return "OFF"
+}
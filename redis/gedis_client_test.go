@@ -0,0 +1,108 @@
+package gedis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testClientLine = "id=3 addr=127.0.0.1:52555 laddr=127.0.0.1:6379 fd=8 name=worker age=120 idle=5 " +
+	"flags=N db=2 sub=1 psub=0 ssub=0 multi=-1 qbuf=26 qbuf-free=20448 argv-mem=10 multi-mem=0 " +
+	"rbs=1024 rbp=0 obl=0 oll=0 omem=0 tot-mem=22298 events=r cmd=client|list user=default " +
+	"redir=-1 resp=3 lib-name=gedis lib-ver=1.0"
+
+func TestParseClientInfo(t *testing.T) {
+	c := ParseClientInfo(testClientLine)
+	want := ClientInfo{
+		ID: 3, Addr: "127.0.0.1:52555", LAddr: "127.0.0.1:6379", FD: 8, Name: "worker",
+		Age: 120 * time.Second, Idle: 5 * time.Second, Flags: "N", DB: 2, Sub: 1,
+		Multi: -1, QBuf: 26, QBufFree: 20448, ArgvMem: 10, TotMem: 22298, Events: "r",
+		Cmd: "client|list", User: "default", Redir: -1, Resp: 3, LibName: "gedis", LibVer: "1.0",
+	}
+	fields := c.Fields
+	c.Fields = nil
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("ParseClientInfo =\n%+v\nwant\n%+v", c, want)
+	}
+	// 未映射到结构体的字段保留在Fields中
+	if fields["rbs"] != "1024" || fields["multi-mem"] != "0" {
+		t.Errorf("Fields = %v", fields)
+	}
+}
+
+func TestParseClientInfoEmptyValues(t *testing.T) {
+	c := ParseClientInfo("id=7 addr=/tmp/redis.sock:0 name= cmd= user=default")
+	if c.ID != 7 || c.Name != "" || c.Cmd != "" || c.User != "default" || c.Addr != "/tmp/redis.sock:0" {
+		t.Errorf("ParseClientInfo = %+v", c)
+	}
+	if _, ok := c.Fields["name"]; !ok {
+		t.Errorf("empty name field is missing: %v", c.Fields)
+	}
+}
+
+func TestParseClientList(t *testing.T) {
+	text := testClientLine + "\n" +
+		"id=4 addr=10.0.0.1:40000 name= cmd=get user=app\n" +
+		"\n"
+	clients := ParseClientList(text)
+	if len(clients) != 2 {
+		t.Fatalf("len = %d", len(clients))
+	}
+	if clients[0].ID != 3 || clients[1].ID != 4 || clients[1].User != "app" || clients[1].Cmd != "get" {
+		t.Errorf("ParseClientList = %+v", clients)
+	}
+	if len(ParseClientList("")) != 0 {
+		t.Error("ParseClientList(\"\") is not empty")
+	}
+}
+
+// 记录归还的连接
+type recordPool struct {
+	put []*Gedis
+}
+
+func (p *recordPool) Get() (*Gedis, error) { return nil, ErrPoolExhausted }
+func (p *recordPool) Put(g *Gedis)         { p.put = append(p.put, g) }
+
+func TestClientReplyQueued(t *testing.T) {
+	g, fc := newFakeGedis()
+	p := g.Pipeline()
+	if err := p.ClientReply(ClientReplyOff); err != ErrNotQueueable {
+		t.Errorf("ClientReply err = %v, want ErrNotQueueable", err)
+	}
+	if err := p.Send("SET", "k", "v"); err != ErrNotQueueable {
+		t.Errorf("Send err = %v, want ErrNotQueueable", err)
+	}
+	if p.Len() != 0 || fc.written.Len() != 0 {
+		t.Errorf("Len = %d, written %q, want nothing queued or sent", p.Len(), fc.written.String())
+	}
+}
+
+func TestClientReplyRestoredOnClose(t *testing.T) {
+	g, fc := newFakeGedis("+OK\r\n")
+	pool := &recordPool{}
+	g.Pool = pool
+	if err := g.ClientReply(ClientReplyOff); err != nil {
+		t.Fatalf("ClientReply: %v", err)
+	}
+	if err := g.Send("SET", "k", "v"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	g.Close()
+	want := respCommand("CLIENT", "REPLY", "OFF") + respCommand("SET", "k", "v") + respCommand("CLIENT", "REPLY", "ON")
+	if fc.written.String() != want {
+		t.Errorf("written = %q, want %q", fc.written.String(), want)
+	}
+	if len(pool.put) != 1 || g.replyOff || fc.closed {
+		t.Errorf("put %d, replyOff %v, closed %v, want the connection returned with REPLY ON", len(pool.put), g.replyOff, fc.closed)
+	}
+
+	// 恢复失败时连接被关闭
+	g, fc = newFakeGedis()
+	g.Pool = pool
+	g.ClientReply(ClientReplySkip)
+	g.Close()
+	if !fc.closed {
+		t.Error("connection that failed to restore REPLY ON was not closed")
+	}
+}
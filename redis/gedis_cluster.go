@@ -0,0 +1,448 @@
+package gedis
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CLUSTER相关的命令，需要连接到cluster模式的节点执行
+
+// CLUSTER INFO的返回值，未列出的字段保存在Fields中
+type ClusterInfo struct {
+	// "ok"或"fail"
+	State                 string
+	SlotsAssigned         int64
+	SlotsOK               int64
+	SlotsPFail            int64
+	SlotsFail             int64
+	KnownNodes            int64
+	// 至少负责一个slot的master数
+	Size                  int64
+	CurrentEpoch          int64
+	MyEpoch               int64
+	StatsMessagesSent     int64
+	StatsMessagesReceived int64
+
+	Fields                map[string]string
+}
+
+func (g *Gedis)ClusterInfo() (*ClusterInfo, error) {
+	text, err := g.Cmd("CLUSTER", "INFO").Str()
+	if err != nil {
+		return nil, err
+	}
+	// 与INFO的格式相同，只是没有section
+	info := ParseInfo(text)
+	const s = ""
+	return &ClusterInfo{
+		State: info.Get(s, "cluster_state"),
+		SlotsAssigned: info.Int(s, "cluster_slots_assigned"),
+		SlotsOK: info.Int(s, "cluster_slots_ok"),
+		SlotsPFail: info.Int(s, "cluster_slots_pfail"),
+		SlotsFail: info.Int(s, "cluster_slots_fail"),
+		KnownNodes: info.Int(s, "cluster_known_nodes"),
+		Size: info.Int(s, "cluster_size"),
+		CurrentEpoch: info.Int(s, "cluster_current_epoch"),
+		MyEpoch: info.Int(s, "cluster_my_epoch"),
+		StatsMessagesSent: info.Int(s, "cluster_stats_messages_sent"),
+		StatsMessagesReceived: info.Int(s, "cluster_stats_messages_received"),
+		Fields: info.Sections[s],
+	}, nil
+}
+
+// 连续的slot区间[Start, End]
+type SlotRange struct {
+	Start int
+	End   int
+}
+
+// CLUSTER NODES中的一个节点
+type ClusterNode struct {
+	ID          string
+	// ip:port
+	Addr        string
+	Host        string
+	Port        int
+	// 集群总线端口
+	BusPort     int
+	Hostname    string
+	// 如myself、master、slave、fail?、fail、handshake、noaddr、nofailover
+	Flags       []string
+	// 副本所属master的ID，master为""
+	MasterID    string
+	PingSent    time.Time
+	PongRecv    time.Time
+	ConfigEpoch int64
+	// "connected"或"disconnected"
+	LinkState   string
+	Slots       []SlotRange
+	// 正在迁出的slot，value为目标节点的ID
+	Migrating   map[int]string
+	// 正在迁入的slot，value为源节点的ID
+	Importing   map[int]string
+}
+
+func (n *ClusterNode)HasFlag(flag string) bool {
+	for _, f := range n.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *ClusterNode)IsMaster() bool {
+	return n.HasFlag("master")
+}
+
+// 是否为执行命令的节点
+func (n *ClusterNode)Myself() bool {
+	return n.HasFlag("myself")
+}
+
+// 解析CLUSTER NODES返回的文本，每一行为一个节点
+func ParseClusterNodes(text string) ([]ClusterNode, error) {
+	nodes := make([]ClusterNode, 0)
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		n, err := ParseClusterNode(line)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, *n)
+	}
+	return nodes, nil
+}
+
+// 解析CLUSTER NODES中的一行:
+//
+//	<id> <ip:port@cport[,hostname]> <flags> <master> <ping-sent> <pong-recv> <config-epoch> <link-state> <slot> ...
+func ParseClusterNode(line string) (*ClusterNode, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 8 {
+		return nil, errors.New("line is not formatted as a CLUSTER NODES line")
+	}
+	n := &ClusterNode{
+		ID: fields[0],
+		Flags: strings.Split(fields[2], ","),
+		LinkState: fields[7],
+	}
+	addr, hostname, _ := strings.Cut(fields[1], ",")
+	n.Hostname = hostname
+	addr, bus, _ := strings.Cut(addr, "@")
+	n.Addr = addr
+	if i := strings.LastIndexByte(addr, ':'); i >= 0 {
+		n.Host = addr[:i]
+		n.Port, _ = strconv.Atoi(addr[i + 1:])
+	}
+	n.BusPort, _ = strconv.Atoi(bus)
+	if fields[3] != "-" {
+		n.MasterID = fields[3]
+	}
+	pingSent, err := strconv.ParseInt(fields[4], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if pingSent > 0 {
+		n.PingSent = time.UnixMilli(pingSent)
+	}
+	pongRecv, err := strconv.ParseInt(fields[5], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if pongRecv > 0 {
+		n.PongRecv = time.UnixMilli(pongRecv)
+	}
+	if n.ConfigEpoch, err = strconv.ParseInt(fields[6], 10, 64); err != nil {
+		return nil, err
+	}
+	for _, s := range fields[8:] {
+		// [slot->-nodeid] 迁出中，[slot-<-nodeid] 迁入中
+		if strings.HasPrefix(s, "[") {
+			s = strings.Trim(s, "[]")
+			if slot, id, ok := strings.Cut(s, "->-"); ok {
+				i, err := strconv.Atoi(slot)
+				if err != nil {
+					return nil, err
+				}
+				if n.Migrating == nil {
+					n.Migrating = make(map[int]string)
+				}
+				n.Migrating[i] = id
+			} else if slot, id, ok := strings.Cut(s, "-<-"); ok {
+				i, err := strconv.Atoi(slot)
+				if err != nil {
+					return nil, err
+				}
+				if n.Importing == nil {
+					n.Importing = make(map[int]string)
+				}
+				n.Importing[i] = id
+			}
+			continue
+		}
+		start, end, isRange := strings.Cut(s, "-")
+		if !isRange {
+			end = start
+		}
+		a, err := strconv.Atoi(start)
+		if err != nil {
+			return nil, err
+		}
+		b, err := strconv.Atoi(end)
+		if err != nil {
+			return nil, err
+		}
+		n.Slots = append(n.Slots, SlotRange{a, b})
+	}
+	return n, nil
+}
+
+func (g *Gedis)ClusterNodes() ([]ClusterNode, error) {
+	text, err := g.Cmd("CLUSTER", "NODES").Str()
+	if err != nil {
+		return nil, err
+	}
+	return ParseClusterNodes(text)
+}
+
+// CLUSTER SLOTS中的一个slot区间及负责该区间的节点，第一个节点为master
+type ClusterSlot struct {
+	SlotRange
+	Nodes []ClusterSlotNode
+}
+
+type ClusterSlotNode struct {
+	Host string
+	Port int
+	ID   string
+}
+
+// CLUSTER SLOTS在Redis 7.0之后已被CLUSTER SHARDS取代
+func (g *Gedis)ClusterSlots() ([]ClusterSlot, error) {
+	r := g.Cmd("CLUSTER", "SLOTS")
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	slots := make([]ClusterSlot, len(r.Children))
+	for i, c := range r.Children {
+		if len(c.Children) < 3 {
+			return nil, errors.New("reply is not formatted as a CLUSTER SLOTS entry")
+		}
+		start, err := c.Children[0].Int64()
+		if err != nil {
+			return nil, err
+		}
+		end, err := c.Children[1].Int64()
+		if err != nil {
+			return nil, err
+		}
+		slots[i].SlotRange = SlotRange{int(start), int(end)}
+		for _, nr := range c.Children[2:] {
+			// [ip, port, id, metadata...]
+			if len(nr.Children) < 2 {
+				return nil, errors.New("reply is not formatted as a CLUSTER SLOTS node")
+			}
+			node := ClusterSlotNode{}
+			if node.Host, err = nr.Children[0].Str(); err != nil {
+				return nil, err
+			}
+			port, err := nr.Children[1].Int64()
+			if err != nil {
+				return nil, err
+			}
+			node.Port = int(port)
+			if len(nr.Children) > 2 {
+				node.ID, _ = nr.Children[2].Str()
+			}
+			slots[i].Nodes = append(slots[i].Nodes, node)
+		}
+	}
+	return slots, nil
+}
+
+// CLUSTER SHARDS中的一个分片
+type ClusterShard struct {
+	Slots []SlotRange
+	Nodes []ClusterShardNode
+}
+
+type ClusterShardNode struct {
+	ID                string
+	Endpoint          string
+	IP                string
+	Hostname          string
+	Port              int
+	TLSPort           int
+	// "master"或"replica"
+	Role              string
+	ReplicationOffset int64
+	// "online"、"failed"或"loading"
+	Health            string
+}
+
+func (g *Gedis)ClusterShards() ([]ClusterShard, error) {
+	r := g.Cmd("CLUSTER", "SHARDS")
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	shards := make([]ClusterShard, len(r.Children))
+	for i, c := range r.Children {
+		m, err := kvReplies(c)
+		if err != nil {
+			return nil, err
+		}
+		if slots, ok := m["slots"]; ok {
+			list, err := intList(slots)
+			if err != nil {
+				return nil, err
+			}
+			for j := 0; j + 1 < len(list); j += 2 {
+				shards[i].Slots = append(shards[i].Slots, SlotRange{int(list[j]), int(list[j + 1])})
+			}
+		}
+		if nodes, ok := m["nodes"]; ok {
+			for _, nr := range nodes.Children {
+				nm, err := kvReplies(nr)
+				if err != nil {
+					return nil, err
+				}
+				shards[i].Nodes = append(shards[i].Nodes, ClusterShardNode{
+					ID: kvStr(nm, "id"),
+					Endpoint: kvStr(nm, "endpoint"),
+					IP: kvStr(nm, "ip"),
+					Hostname: kvStr(nm, "hostname"),
+					Port: int(kvInt(nm, "port")),
+					TLSPort: int(kvInt(nm, "tls-port")),
+					Role: kvStr(nm, "role"),
+					ReplicationOffset: kvInt(nm, "replication-offset"),
+					Health: kvStr(nm, "health"),
+				})
+			}
+		}
+	}
+	return shards, nil
+}
+
+// 返回key所属的slot
+func (g *Gedis)ClusterKeySlot(key string) (int64, error) {
+	return g.Cmd("CLUSTER", "KEYSLOT", key).Int64()
+}
+
+// 返回当前节点上slot中的key数量
+func (g *Gedis)ClusterCountKeysInSlot(slot int) (int64, error) {
+	return g.Cmd("CLUSTER", "COUNTKEYSINSLOT", slot).Int64()
+}
+
+// 返回当前节点上slot中最多count个key，用于迁移slot
+func (g *Gedis)ClusterGetKeysInSlot(slot int, count int64) ([]string, error) {
+	return g.Cmd("CLUSTER", "GETKEYSINSLOT", slot, count).List()
+}
+
+func (g *Gedis)ClusterAddSlots(slots... int) (string, error) {
+	return g.Cmd("CLUSTER", "ADDSLOTS", slots).Str()
+}
+
+func (g *Gedis)ClusterAddSlotsRange(ranges... SlotRange) (string, error) {
+	return g.Cmd("CLUSTER", "ADDSLOTSRANGE", slotRangeArgs(ranges)).Str()
+}
+
+func (g *Gedis)ClusterDelSlots(slots... int) (string, error) {
+	return g.Cmd("CLUSTER", "DELSLOTS", slots).Str()
+}
+
+func slotRangeArgs(ranges []SlotRange) []interface{} {
+	args := make([]interface{}, 0, len(ranges) * 2)
+	for _, r := range ranges {
+		args = append(args, r.Start, r.End)
+	}
+	return args
+}
+
+// 在目标节点上将slot标记为从nodeID迁入
+func (g *Gedis)ClusterSetSlotImporting(slot int, nodeID string) (string, error) {
+	return g.Cmd("CLUSTER", "SETSLOT", slot, "IMPORTING", nodeID).Str()
+}
+
+// 在源节点上将slot标记为迁出到nodeID
+func (g *Gedis)ClusterSetSlotMigrating(slot int, nodeID string) (string, error) {
+	return g.Cmd("CLUSTER", "SETSLOT", slot, "MIGRATING", nodeID).Str()
+}
+
+// 将slot分配给nodeID，迁移完成后在源节点和目标节点上执行
+func (g *Gedis)ClusterSetSlotNode(slot int, nodeID string) (string, error) {
+	return g.Cmd("CLUSTER", "SETSLOT", slot, "NODE", nodeID).Str()
+}
+
+// 清除slot的迁入/迁出状态
+func (g *Gedis)ClusterSetSlotStable(slot int) (string, error) {
+	return g.Cmd("CLUSTER", "SETSLOT", slot, "STABLE").Str()
+}
+
+// 将host:port上的节点加入集群
+func (g *Gedis)ClusterMeet(host string, port int) (string, error) {
+	return g.Cmd("CLUSTER", "MEET", host, port).Str()
+}
+
+// 从当前节点的节点表中移除nodeID，需要在60秒内在所有节点上执行
+func (g *Gedis)ClusterForget(nodeID string) (string, error) {
+	return g.Cmd("CLUSTER", "FORGET", nodeID).Str()
+}
+
+// 将当前节点设置为nodeID的副本
+func (g *Gedis)ClusterReplicate(nodeID string) (string, error) {
+	return g.Cmd("CLUSTER", "REPLICATE", nodeID).Str()
+}
+
+// 重置当前节点的集群状态，hard为true时同时重置节点ID及epoch
+func (g *Gedis)ClusterReset(hard bool) (string, error) {
+	if hard {
+		return g.Cmd("CLUSTER", "RESET", "HARD").Str()
+	}
+	return g.Cmd("CLUSTER", "RESET", "SOFT").Str()
+}
+
+// CLUSTER FAILOVER的模式，为空时进行需要master确认的手动failover
+type ClusterFailoverMode string
+
+const (
+	ClusterFailoverDefault  ClusterFailoverMode = ""
+	// 不与master握手，master不可达时使用
+	ClusterFailoverForce    ClusterFailoverMode = "FORCE"
+	// 不需要其它master投票，直接接管
+	ClusterFailoverTakeover ClusterFailoverMode = "TAKEOVER"
+)
+
+// 在副本上执行，将其提升为master
+func (g *Gedis)ClusterFailover(mode ClusterFailoverMode) (string, error) {
+	if mode == ClusterFailoverDefault {
+		return g.Cmd("CLUSTER", "FAILOVER").Str()
+	}
+	return g.Cmd("CLUSTER", "FAILOVER", string(mode)).Str()
+}
+
+func (g *Gedis)ClusterMyID() (string, error) {
+	return g.Cmd("CLUSTER", "MYID").Str()
+}
+
+// Redis 7.2+
+func (g *Gedis)ClusterMyShardID() (string, error) {
+	return g.Cmd("CLUSTER", "MYSHARDID").Str()
+}
@@ -0,0 +1,88 @@
+package gedis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseClusterNode(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want ClusterNode
+	}{
+		{"master with slots",
+			"07c37dfeb235213a872192d90877d0cd55635b91 127.0.0.1:30004@31004 myself,master - 0 1426238317239 4 connected 0-5460 5462 10923-16383",
+			ClusterNode{
+				ID: "07c37dfeb235213a872192d90877d0cd55635b91", Addr: "127.0.0.1:30004", Host: "127.0.0.1", Port: 30004, BusPort: 31004,
+				Flags: []string{"myself", "master"}, PongRecv: time.UnixMilli(1426238317239), ConfigEpoch: 4, LinkState: "connected",
+				Slots: []SlotRange{{0, 5460}, {5462, 5462}, {10923, 16383}},
+			}},
+		{"replica with hostname",
+			"e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca 10.0.0.2:30002@31002,redis-2.example.com slave 67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1 1426238316232 1426238317741 2 disconnected",
+			ClusterNode{
+				ID: "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca", Addr: "10.0.0.2:30002", Host: "10.0.0.2", Port: 30002, BusPort: 31002,
+				Hostname: "redis-2.example.com", Flags: []string{"slave"}, MasterID: "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1",
+				PingSent: time.UnixMilli(1426238316232), PongRecv: time.UnixMilli(1426238317741), ConfigEpoch: 2, LinkState: "disconnected",
+			}},
+		{"migrating and importing",
+			"292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f 127.0.0.1:30003@31003 master - 0 1426238318243 3 connected 10923-16382 [16383->-e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca] [93-<-67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1]",
+			ClusterNode{
+				ID: "292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f", Addr: "127.0.0.1:30003", Host: "127.0.0.1", Port: 30003, BusPort: 31003,
+				Flags: []string{"master"}, PongRecv: time.UnixMilli(1426238318243), ConfigEpoch: 3, LinkState: "connected",
+				Slots:     []SlotRange{{10923, 16382}},
+				Migrating: map[int]string{16383: "e7d1eecce10fd6bb5eb35b9f99a514335d9ba9ca"},
+				Importing: map[int]string{93: "67ed2db8d677e59ec4a4cefb06858cf2a1a89fa1"},
+			}},
+		{"ipv6 without address",
+			"6ec23923021cf3ffec47632106199cb7f496ce01 :0@0 master,noaddr - 1426238316232 0 0 disconnected",
+			ClusterNode{
+				ID: "6ec23923021cf3ffec47632106199cb7f496ce01", Addr: ":0", Flags: []string{"master", "noaddr"},
+				PingSent: time.UnixMilli(1426238316232), LinkState: "disconnected",
+			}},
+		{"ipv6",
+			"824fe116063bc5fcf9f4ffd895bc17aee7731ac3 [::1]:30006@31006 slave 292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f 0 1426238317741 6 connected",
+			ClusterNode{
+				ID: "824fe116063bc5fcf9f4ffd895bc17aee7731ac3", Addr: "[::1]:30006", Host: "[::1]", Port: 30006, BusPort: 31006,
+				Flags: []string{"slave"}, MasterID: "292f8b365bb7edb5e285caf0b7e6ddc7265d2f4f",
+				PongRecv: time.UnixMilli(1426238317741), ConfigEpoch: 6, LinkState: "connected",
+			}},
+	}
+	for _, tt := range tests {
+		n, err := ParseClusterNode(tt.line)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*n, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, *n, tt.want)
+		}
+	}
+}
+
+func TestParseClusterNodeInvalid(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"id 127.0.0.1:1@2 master - 0 0",
+		"id 127.0.0.1:1@2 master - x 0 0 connected",
+		"id 127.0.0.1:1@2 master - 0 0 0 connected a-b",
+		"id 127.0.0.1:1@2 master - 0 0 0 connected [x->-id]",
+	} {
+		if n, err := ParseClusterNode(line); err == nil {
+			t.Errorf("ParseClusterNode(%q) = %+v, want error", line, n)
+		}
+	}
+}
+
+func TestParseClusterNodes(t *testing.T) {
+	text := "a 127.0.0.1:1@11 myself,master - 0 0 1 connected 0-100\n" +
+		"b 127.0.0.1:2@12 slave a 0 0 1 connected\n\n"
+	nodes, err := ParseClusterNodes(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || !nodes[0].Myself() || !nodes[0].IsMaster() || nodes[1].IsMaster() || nodes[1].MasterID != "a" {
+		t.Fatalf("ParseClusterNodes = %+v", nodes)
+	}
+}
@@ -0,0 +1,355 @@
+package gedis
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SLOWLOG、LATENCY、MEMORY等诊断相关的命令
+
+// SLOWLOG GET返回的一条慢查询
+type SlowLogEntry struct {
+	ID         int64
+	Time       time.Time
+	Duration   time.Duration
+	Args       []string
+	ClientAddr string
+	ClientName string
+}
+
+// 返回最近count条慢查询，count为负数时返回全部
+func (g *Gedis)SlowLogGet(count int64) ([]SlowLogEntry, error) {
+	return parseSlowLog(g.Cmd("SLOWLOG", "GET", count))
+}
+
+func parseSlowLog(r *Reply) ([]SlowLogEntry, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	entries := make([]SlowLogEntry, len(r.Children))
+	for i, c := range r.Children {
+		if len(c.Children) < 4 {
+			return nil, errors.New("reply is not formatted as a slowlog entry")
+		}
+		e := &entries[i]
+		var err error
+		if e.ID, err = c.Children[0].Int64(); err != nil {
+			return nil, err
+		}
+		ts, err := c.Children[1].Int64()
+		if err != nil {
+			return nil, err
+		}
+		e.Time = time.Unix(ts, 0)
+		us, err := c.Children[2].Int64()
+		if err != nil {
+			return nil, err
+		}
+		e.Duration = time.Duration(us) * time.Microsecond
+		if e.Args, err = c.Children[3].List(); err != nil {
+			return nil, err
+		}
+		// 客户端地址和名称在Redis 4.0之后才有
+		if len(c.Children) >= 6 {
+			e.ClientAddr, _ = c.Children[4].Str()
+			e.ClientName, _ = c.Children[5].Str()
+		}
+	}
+	return entries, nil
+}
+
+func (g *Gedis)SlowLogLen() (int64, error) {
+	return g.Cmd("SLOWLOG", "LEN").Int64()
+}
+
+func (g *Gedis)SlowLogReset() (string, error) {
+	return g.Cmd("SLOWLOG", "RESET").Str()
+}
+
+// LATENCY LATEST返回的一个事件
+type LatencyEvent struct {
+	Event  string
+	// 最近一次超过阈值的时间
+	Time   time.Time
+	Latest time.Duration
+	Max    time.Duration
+}
+
+func (g *Gedis)LatencyLatest() ([]LatencyEvent, error) {
+	return parseLatencyEvents(g.Cmd("LATENCY", "LATEST"))
+}
+
+func parseLatencyEvents(r *Reply) ([]LatencyEvent, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	events := make([]LatencyEvent, len(r.Children))
+	for i, c := range r.Children {
+		if len(c.Children) < 4 {
+			return nil, errors.New("reply is not formatted as a latency event")
+		}
+		e := &events[i]
+		var err error
+		if e.Event, err = c.Children[0].Str(); err != nil {
+			return nil, err
+		}
+		ts, err := c.Children[1].Int64()
+		if err != nil {
+			return nil, err
+		}
+		e.Time = time.Unix(ts, 0)
+		latest, err := c.Children[2].Int64()
+		if err != nil {
+			return nil, err
+		}
+		e.Latest = time.Duration(latest) * time.Millisecond
+		max, err := c.Children[3].Int64()
+		if err != nil {
+			return nil, err
+		}
+		e.Max = time.Duration(max) * time.Millisecond
+	}
+	return events, nil
+}
+
+// LATENCY HISTORY中的一个采样
+type LatencySample struct {
+	Time    time.Time
+	Latency time.Duration
+}
+
+func (g *Gedis)LatencyHistory(event string) ([]LatencySample, error) {
+	r := g.Cmd("LATENCY", "HISTORY", event)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	samples := make([]LatencySample, len(r.Children))
+	for i, c := range r.Children {
+		pair, err := intList(c)
+		if err != nil {
+			return nil, err
+		}
+		if len(pair) != 2 {
+			return nil, errors.New("reply is not formatted as a latency sample")
+		}
+		samples[i] = LatencySample{
+			Time: time.Unix(pair[0], 0),
+			Latency: time.Duration(pair[1]) * time.Millisecond,
+		}
+	}
+	return samples, nil
+}
+
+// 重置事件的延迟数据，不指定events时重置全部，返回重置的事件数
+func (g *Gedis)LatencyReset(events... string) (int64, error) {
+	return g.Cmd("LATENCY", "RESET", events).Int64()
+}
+
+// 返回人类可读的延迟分析报告
+func (g *Gedis)LatencyDoctor() (string, error) {
+	return g.Cmd("LATENCY", "DOCTOR").Str()
+}
+
+// LATENCY HISTOGRAM中一个命令的延迟分布
+type LatencyHistogram struct {
+	Calls   int64
+	// key为桶的上界(微秒)，value为落入该桶的累计调用次数
+	Buckets map[int64]int64
+}
+
+// 返回命令的延迟分布，不指定commands时返回所有执行过的命令，key为命令名
+func (g *Gedis)LatencyHistogram(commands... string) (map[string]LatencyHistogram, error) {
+	return parseLatencyHistograms(g.Cmd("LATENCY", "HISTOGRAM", commands))
+}
+
+func parseLatencyHistograms(r *Reply) (map[string]LatencyHistogram, error) {
+	m, err := kvReplies(r)
+	if err != nil {
+		return nil, err
+	}
+	histograms := make(map[string]LatencyHistogram, len(m))
+	for cmd, r := range m {
+		hm, err := kvReplies(r)
+		if err != nil {
+			return nil, err
+		}
+		h := LatencyHistogram{Calls: kvInt(hm, "calls"), Buckets: make(map[int64]int64)}
+		if buckets, ok := hm["histogram_usec"]; ok {
+			list, err := intList(buckets)
+			if err != nil {
+				return nil, err
+			}
+			for i := 0; i + 1 < len(list); i += 2 {
+				h.Buckets[list[i]] = list[i + 1]
+			}
+		}
+		histograms[cmd] = h
+	}
+	return histograms, nil
+}
+
+// 返回key及其值占用的内存字节数，samples为0时使用服务端默认的采样数，key不存在时返回ErrNil
+func (g *Gedis)MemoryUsage(key string, samples int64) (int64, error) {
+	if samples > 0 {
+		return g.Cmd("MEMORY", "USAGE", key, "SAMPLES", samples).Int64()
+	}
+	return g.Cmd("MEMORY", "USAGE", key).Int64()
+}
+
+// MEMORY STATS的返回值，单位为字节，未列出的字段保存在Fields中
+type MemoryStats struct {
+	PeakAllocated      int64
+	TotalAllocated     int64
+	StartupAllocated   int64
+	ReplicationBacklog int64
+	ClientsSlaves      int64
+	ClientsNormal      int64
+	AOFBuffer          int64
+	LuaCaches          int64
+	OverheadTotal      int64
+	KeysCount          int64
+	KeysBytesPerKey    int64
+	DatasetBytes       int64
+	DatasetPercentage  float64
+	PeakPercentage     float64
+	Fragmentation      float64
+	FragmentationBytes int64
+	// 每个db的哈希表开销，key为db的下标
+	DB                 map[int]MemoryStatsDB
+	Fields             map[string]string
+}
+
+type MemoryStatsDB struct {
+	OverheadHashtableMain    int64
+	OverheadHashtableExpires int64
+}
+
+func (g *Gedis)MemoryStats() (*MemoryStats, error) {
+	return parseMemoryStats(g.Cmd("MEMORY", "STATS"))
+}
+
+func parseMemoryStats(r *Reply) (*MemoryStats, error) {
+	m, err := kvReplies(r)
+	if err != nil {
+		return nil, err
+	}
+	stats := &MemoryStats{
+		PeakAllocated: kvInt(m, "peak.allocated"),
+		TotalAllocated: kvInt(m, "total.allocated"),
+		StartupAllocated: kvInt(m, "startup.allocated"),
+		ReplicationBacklog: kvInt(m, "replication.backlog"),
+		ClientsSlaves: kvInt(m, "clients.slaves"),
+		ClientsNormal: kvInt(m, "clients.normal"),
+		AOFBuffer: kvInt(m, "aof.buffer"),
+		LuaCaches: kvInt(m, "lua.caches"),
+		OverheadTotal: kvInt(m, "overhead.total"),
+		KeysCount: kvInt(m, "keys.count"),
+		KeysBytesPerKey: kvInt(m, "keys.bytes-per-key"),
+		DatasetBytes: kvInt(m, "dataset.bytes"),
+		DatasetPercentage: kvFloat(m, "dataset.percentage"),
+		PeakPercentage: kvFloat(m, "peak.percentage"),
+		Fragmentation: kvFloat(m, "fragmentation"),
+		FragmentationBytes: kvInt(m, "fragmentation.bytes"),
+		DB: make(map[int]MemoryStatsDB),
+		Fields: make(map[string]string),
+	}
+	for k, r := range m {
+		if strings.HasPrefix(k, "db.") && r.Type == MultiReply {
+			db, err := strconv.Atoi(k[3:])
+			if err != nil {
+				continue
+			}
+			dm, err := kvReplies(r)
+			if err != nil {
+				return nil, err
+			}
+			stats.DB[db] = MemoryStatsDB{
+				OverheadHashtableMain: kvInt(dm, "overhead.hashtable.main"),
+				OverheadHashtableExpires: kvInt(dm, "overhead.hashtable.expires"),
+			}
+			continue
+		}
+		if r.Type != MultiReply {
+			stats.Fields[k] = kvStr(m, k)
+		}
+	}
+	return stats, nil
+}
+
+// 返回人类可读的内存分析报告
+func (g *Gedis)MemoryDoctor() (string, error) {
+	return g.Cmd("MEMORY", "DOCTOR").Str()
+}
+
+// 让内存分配器(jemalloc)释放脏页
+func (g *Gedis)MemoryPurge() (string, error) {
+	return g.Cmd("MEMORY", "PURGE").Str()
+}
+
+// 一个节点的诊断数据快照
+type Diagnostics struct {
+	Addr        string
+	SlowLog     []SlowLogEntry
+	Latency     []LatencyEvent
+	MemoryStats *MemoryStats
+}
+
+// 收集g所连接节点的慢查询(最近slowLogCount条)、延迟事件及内存统计
+func (g *Gedis)CollectDiagnostics(slowLogCount int64) (*Diagnostics, error) {
+	d := &Diagnostics{Addr: g.conn.Conn.RemoteAddr().String()}
+	var err error
+	if d.SlowLog, err = g.SlowLogGet(slowLogCount); err != nil {
+		return nil, err
+	}
+	if d.Latency, err = g.LatencyLatest(); err != nil {
+		return nil, err
+	}
+	if d.MemoryStats, err = g.MemoryStats(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// 收集每一个分片的诊断数据
+func (s *ShardedGedis)CollectDiagnostics(slowLogCount int64) ([]*Diagnostics, error) {
+	shards := s.AllShards()
+	ds := make([]*Diagnostics, 0, len(shards))
+	for _, g := range shards {
+		d, err := g.CollectDiagnostics(slowLogCount)
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
+// 从连接池中获取一个ShardedGedis，收集每一个分片的诊断数据
+func (p *ShardedGedisPool)CollectDiagnostics(slowLogCount int64) ([]*Diagnostics, error) {
+	sg, err := p.Get()
+	if err != nil {
+		return nil, err
+	}
+	defer sg.Close()
+	return sg.CollectDiagnostics(slowLogCount)
+}
@@ -0,0 +1,99 @@
+package gedis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSlowLog(t *testing.T) {
+	args := respArray(respBulk("GET"), respBulk("k"))
+	r := mustReply(t, respArray(
+		respArray(":14\r\n", ":1700000000\r\n", ":1500\r\n", args, respBulk("127.0.0.1:52555"), respBulk("worker")),
+		// Redis 4.0之前没有客户端地址和名称
+		respArray(":13\r\n", ":1700000001\r\n", ":20\r\n", args),
+	))
+	entries, err := parseSlowLog(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []SlowLogEntry{
+		{ID: 14, Time: time.Unix(1700000000, 0), Duration: 1500 * time.Microsecond, Args: []string{"GET", "k"},
+			ClientAddr: "127.0.0.1:52555", ClientName: "worker"},
+		{ID: 13, Time: time.Unix(1700000001, 0), Duration: 20 * time.Microsecond, Args: []string{"GET", "k"}},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("parseSlowLog = %+v, want %+v", entries, want)
+	}
+
+	for _, bad := range []string{":1\r\n", respArray(respArray(":1\r\n", ":2\r\n", ":3\r\n"))} {
+		if _, err := parseSlowLog(mustReply(t, bad)); err == nil {
+			t.Errorf("parseSlowLog(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseLatencyEvents(t *testing.T) {
+	r := mustReply(t, respArray(respArray(respBulk("command"), ":1700000000\r\n", ":25\r\n", ":300\r\n")))
+	events, err := parseLatencyEvents(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []LatencyEvent{{Event: "command", Time: time.Unix(1700000000, 0), Latest: 25 * time.Millisecond, Max: 300 * time.Millisecond}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("parseLatencyEvents = %+v, want %+v", events, want)
+	}
+	if _, err := parseLatencyEvents(mustReply(t, respArray(respArray(respBulk("command"))))); err == nil {
+		t.Error("short latency event was accepted")
+	}
+}
+
+func TestParseLatencyHistograms(t *testing.T) {
+	r := mustReply(t, respArray(
+		respBulk("set"), respArray(respBulk("calls"), ":3\r\n", respBulk("histogram_usec"), respArray(":1\r\n", ":2\r\n", ":4\r\n", ":3\r\n")),
+		respBulk("get"), respArray(respBulk("calls"), ":0\r\n"),
+	))
+	histograms, err := parseLatencyHistograms(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]LatencyHistogram{
+		"set": {Calls: 3, Buckets: map[int64]int64{1: 2, 4: 3}},
+		"get": {Calls: 0, Buckets: map[int64]int64{}},
+	}
+	if !reflect.DeepEqual(histograms, want) {
+		t.Errorf("parseLatencyHistograms = %+v, want %+v", histograms, want)
+	}
+}
+
+func TestParseMemoryStats(t *testing.T) {
+	r := mustReply(t, respArray(
+		respBulk("peak.allocated"), ":2000\r\n",
+		respBulk("total.allocated"), ":1000\r\n",
+		respBulk("db.0"), respArray(respBulk("overhead.hashtable.main"), ":72\r\n", respBulk("overhead.hashtable.expires"), ":8\r\n"),
+		respBulk("db.12"), respArray(respBulk("overhead.hashtable.main"), ":16\r\n"),
+		respBulk("dataset.percentage"), respBulk("42.5"),
+		respBulk("allocator.resident"), ":4096\r\n",
+	))
+	stats, err := parseMemoryStats(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.PeakAllocated != 2000 || stats.TotalAllocated != 1000 || stats.DatasetPercentage != 42.5 {
+		t.Errorf("stats = %+v", stats)
+	}
+	wantDB := map[int]MemoryStatsDB{
+		0:  {OverheadHashtableMain: 72, OverheadHashtableExpires: 8},
+		12: {OverheadHashtableMain: 16},
+	}
+	if !reflect.DeepEqual(stats.DB, wantDB) {
+		t.Errorf("DB = %+v, want %+v", stats.DB, wantDB)
+	}
+	// 未识别的字段也保存在Fields中，db.N不在其中
+	if stats.Fields["allocator.resident"] != "4096" || stats.Fields["peak.allocated"] != "2000" {
+		t.Errorf("Fields = %v", stats.Fields)
+	}
+	if _, ok := stats.Fields["db.0"]; ok {
+		t.Errorf("Fields contains db.0: %v", stats.Fields)
+	}
+}
@@ -0,0 +1,257 @@
+package gedis
+
+import "errors"
+
+// 地理位置相关的命令
+
+// 距离单位
+type GeoUnit string
+
+const (
+	Meters     GeoUnit = "m"
+	Kilometers GeoUnit = "km"
+	Miles      GeoUnit = "mi"
+	Feet       GeoUnit = "ft"
+)
+
+// GEOSEARCH按距离排序的方式，空值表示不排序
+type GeoSort string
+
+const (
+	GeoSortNone GeoSort = ""
+	GeoSortAsc  GeoSort = "ASC"  // 由近到远
+	GeoSortDesc GeoSort = "DESC" // 由远到近
+)
+
+// 一个地理位置，Dist/GeoHash只在GEOSEARCH指定WITHDIST/WITHHASH时有值
+type GeoLocation struct {
+	Name      string
+	Longitude float64
+	Latitude  float64
+	Dist      float64
+	GeoHash   int64
+}
+
+// GEOADD的可选参数，NX与XX互斥
+type GeoAddOptions struct {
+	NX bool
+	XX bool
+	// 返回值由新增成员数改为新增及坐标被修改的成员数
+	CH bool
+}
+
+func (o *GeoAddOptions) args() []interface{} {
+	args := make([]interface{}, 0, 2)
+	if o == nil {
+		return args
+	}
+	if o.NX {
+		args = append(args, "NX")
+	} else if o.XX {
+		args = append(args, "XX")
+	}
+	if o.CH {
+		args = append(args, "CH")
+	}
+	return args
+}
+
+// 添加位置，返回新增(CH时为新增及修改)的成员数
+func (g *Gedis)GeoAdd(key string, opt *GeoAddOptions, locations... GeoLocation) (int64, error) {
+	args := make([]interface{}, 0, len(locations) * 3)
+	for _, l := range locations {
+		args = append(args, l.Longitude, l.Latitude, l.Name)
+	}
+	return g.Cmd("GEOADD", key, opt.args(), args).Int64()
+}
+
+// 返回值与members一一对应，不存在的成员对应nil
+func (g *Gedis)GeoPos(key string, members... string) ([]*GeoLocation, error) {
+	r := g.Cmd("GEOPOS", key, members)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply || len(r.Children) != len(members) {
+		return nil, errors.New("reply is not formatted as a GEOPOS reply")
+	}
+	locations := make([]*GeoLocation, len(r.Children))
+	for i, c := range r.Children {
+		if c.Type == NilReply {
+			continue
+		}
+		l := &GeoLocation{Name: members[i]}
+		if err := parseGeoCoord(c, l); err != nil {
+			return nil, err
+		}
+		locations[i] = l
+	}
+	return locations, nil
+}
+
+// 返回两个成员之间的距离，unit为空时使用米，任一成员不存在时返回ErrNil
+func (g *Gedis)GeoDist(key, member1, member2 string, unit GeoUnit) (float64, error) {
+	if unit == "" {
+		unit = Meters
+	}
+	return g.Cmd("GEODIST", key, member1, member2, string(unit)).Float64()
+}
+
+// 返回值与members一一对应，不存在的成员对应""
+func (g *Gedis)GeoHash(key string, members... string) ([]string, error) {
+	return g.Cmd("GEOHASH", key, members).List()
+}
+
+// GEOSEARCH的查询参数:
+// FromMember不为空时使用FROMMEMBER，否则使用FROMLONLAT Longitude Latitude；
+// Radius大于0时使用BYRADIUS，否则使用BYBOX Width Height
+type GeoSearchQuery struct {
+	FromMember string
+	Longitude  float64
+	Latitude   float64
+
+	Radius     float64
+	Width      float64
+	Height     float64
+	// 为空时使用米
+	Unit       GeoUnit
+
+	// 按距离排序，为空时不排序
+	Sort       GeoSort
+	// 大于0时发送COUNT，Any为true时找到Count个结果即返回，不保证是最近的
+	Count      int64
+	Any        bool
+
+	WithCoord  bool
+	WithDist   bool
+	WithHash   bool
+}
+
+func (q *GeoSearchQuery) args() []interface{} {
+	args := make([]interface{}, 0, 12)
+	if q.FromMember != "" {
+		args = append(args, "FROMMEMBER", q.FromMember)
+	} else {
+		args = append(args, "FROMLONLAT", q.Longitude, q.Latitude)
+	}
+	unit := q.Unit
+	if unit == "" {
+		unit = Meters
+	}
+	if q.Radius > 0 {
+		args = append(args, "BYRADIUS", q.Radius, string(unit))
+	} else {
+		args = append(args, "BYBOX", q.Width, q.Height, string(unit))
+	}
+	if q.Sort != GeoSortNone {
+		args = append(args, string(q.Sort))
+	}
+	if q.Count > 0 {
+		args = append(args, "COUNT", q.Count)
+		if q.Any {
+			args = append(args, "ANY")
+		}
+	}
+	return args
+}
+
+// 查询区域内的位置，没有指定WITH*参数时只有Name有值
+func (g *Gedis)GeoSearch(key string, q *GeoSearchQuery) ([]GeoLocation, error) {
+	args := q.args()
+	if q.WithCoord {
+		args = append(args, "WITHCOORD")
+	}
+	if q.WithDist {
+		args = append(args, "WITHDIST")
+	}
+	if q.WithHash {
+		args = append(args, "WITHHASH")
+	}
+	return parseGeoLocations(g.Cmd("GEOSEARCH", key, args), q)
+}
+
+// 将查询结果保存到destination，storeDist为true时以距离作为分值保存，返回结果的成员数
+func (g *Gedis)GeoSearchStore(key, destination string, q *GeoSearchQuery, storeDist bool) (int64, error) {
+	if storeDist {
+		return g.Cmd("GEOSEARCHSTORE", destination, key, q.args(), "STOREDIST").Int64()
+	}
+	return g.Cmd("GEOSEARCHSTORE", destination, key, q.args()).Int64()
+}
+
+// GEOSEARCH的返回值：没有WITH*参数时为成员名的数组，
+// 否则每个元素为 [name, dist?, hash?, [lon, lat]?]，按该顺序出现
+func parseGeoLocations(r *Reply, q *GeoSearchQuery) ([]GeoLocation, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	withAny := q.WithCoord || q.WithDist || q.WithHash
+	locations := make([]GeoLocation, len(r.Children))
+	for i, c := range r.Children {
+		l := &locations[i]
+		if !withAny {
+			name, err := c.Str()
+			if err != nil {
+				return nil, err
+			}
+			l.Name = name
+			continue
+		}
+		if c.Type != MultiReply || len(c.Children) == 0 {
+			return nil, errors.New("reply is not formatted as a GEOSEARCH reply")
+		}
+		fields := c.Children
+		var err error
+		if l.Name, err = fields[0].Str(); err != nil {
+			return nil, err
+		}
+		fields = fields[1:]
+		if q.WithDist {
+			if len(fields) == 0 {
+				return nil, errors.New("reply is not formatted as a GEOSEARCH reply")
+			}
+			if l.Dist, err = fields[0].Float64(); err != nil {
+				return nil, err
+			}
+			fields = fields[1:]
+		}
+		if q.WithHash {
+			if len(fields) == 0 {
+				return nil, errors.New("reply is not formatted as a GEOSEARCH reply")
+			}
+			if l.GeoHash, err = fields[0].Int64(); err != nil {
+				return nil, err
+			}
+			fields = fields[1:]
+		}
+		if q.WithCoord {
+			if len(fields) == 0 {
+				return nil, errors.New("reply is not formatted as a GEOSEARCH reply")
+			}
+			if err = parseGeoCoord(fields[0], l); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return locations, nil
+}
+
+// 解析 [longitude, latitude]
+func parseGeoCoord(r *Reply, l *GeoLocation) error {
+	if r.Type != MultiReply || len(r.Children) != 2 {
+		return errors.New("reply is not formatted as a coordinate")
+	}
+	var err error
+	if l.Longitude, err = r.Children[0].Float64(); err != nil {
+		return err
+	}
+	l.Latitude, err = r.Children[1].Float64()
+	return err
+}
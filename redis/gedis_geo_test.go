@@ -0,0 +1,78 @@
+package gedis
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseGeoLocations(t *testing.T) {
+	coord := respArray(respBulk("13.361389"), respBulk("38.115556"))
+	tests := []struct {
+		name  string
+		q     GeoSearchQuery
+		reply string
+		want  GeoLocation
+	}{
+		{"names only", GeoSearchQuery{}, respArray(respBulk("Palermo")), GeoLocation{Name: "Palermo"}},
+		{"dist", GeoSearchQuery{WithDist: true},
+			respArray(respArray(respBulk("Palermo"), respBulk("190.4424"))),
+			GeoLocation{Name: "Palermo", Dist: 190.4424}},
+		{"hash", GeoSearchQuery{WithHash: true},
+			respArray(respArray(respBulk("Palermo"), ":3479099956230698\r\n")),
+			GeoLocation{Name: "Palermo", GeoHash: 3479099956230698}},
+		{"coord", GeoSearchQuery{WithCoord: true},
+			respArray(respArray(respBulk("Palermo"), coord)),
+			GeoLocation{Name: "Palermo", Longitude: 13.361389, Latitude: 38.115556}},
+		{"all", GeoSearchQuery{WithCoord: true, WithDist: true, WithHash: true},
+			respArray(respArray(respBulk("Palermo"), respBulk("190.4424"), ":3479099956230698\r\n", coord)),
+			GeoLocation{Name: "Palermo", Dist: 190.4424, GeoHash: 3479099956230698, Longitude: 13.361389, Latitude: 38.115556}},
+	}
+	for _, tt := range tests {
+		locations, err := parseGeoLocations(mustReply(t, tt.reply), &tt.q)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if len(locations) != 1 || locations[0] != tt.want {
+			t.Errorf("%s: %+v, want %+v", tt.name, locations, tt.want)
+		}
+	}
+}
+
+func TestParseGeoLocationsInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		q     GeoSearchQuery
+		reply string
+	}{
+		{"error", GeoSearchQuery{}, "-ERR unsupported unit\r\n"},
+		{"not array", GeoSearchQuery{}, ":1\r\n"},
+		{"missing dist", GeoSearchQuery{WithDist: true}, respArray(respArray(respBulk("Palermo")))},
+		{"missing coord", GeoSearchQuery{WithDist: true, WithCoord: true}, respArray(respArray(respBulk("Palermo"), respBulk("1.5")))},
+		{"bad coord", GeoSearchQuery{WithCoord: true}, respArray(respArray(respBulk("Palermo"), respArray(respBulk("1"))))},
+		{"flat item", GeoSearchQuery{WithDist: true}, respArray(respBulk("Palermo"))},
+	}
+	for _, tt := range tests {
+		if _, err := parseGeoLocations(mustReply(t, tt.reply), &tt.q); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestGeoSearchQueryArgs(t *testing.T) {
+	tests := []struct {
+		q    GeoSearchQuery
+		want string
+	}{
+		{GeoSearchQuery{FromMember: "a", Radius: 10}, "[FROMMEMBER a BYRADIUS 10 m]"},
+		{GeoSearchQuery{Longitude: 15, Latitude: 37, Width: 2, Height: 3, Unit: Kilometers, Sort: GeoSortAsc},
+			"[FROMLONLAT 15 37 BYBOX 2 3 km ASC]"},
+		{GeoSearchQuery{FromMember: "a", Radius: 1, Sort: GeoSortDesc, Count: 5, Any: true},
+			"[FROMMEMBER a BYRADIUS 1 m DESC COUNT 5 ANY]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tt.q.args()); got != tt.want {
+			t.Errorf("%+v.args() = %s, want %s", tt.q, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package gedis
+
+import (
+	"errors"
+	"time"
+)
+
+// Hash类型相关的命令
+
+// 设置一个或多个field，返回新增的field数量
+func (g *Gedis)HSet(key string, pairs map[string]interface{}) (int64, error) {
+	return g.Cmd("HSET", key, flattenPairs(pairs)).Int64()
+}
+
+// field不存在时返回ErrNil
+func (g *Gedis)HGet(key, field string) (string, error) {
+	return g.Cmd("HGET", key, field).Str()
+}
+
+// 返回值与fields一一对应，不存在的field对应的元素为nil
+func (g *Gedis)HMGet(key string, fields... string) ([][]byte, error) {
+	return g.Cmd("HMGET", key, fields).releaseListBytes()
+}
+
+func (g *Gedis)HGetAll(key string) (map[string]string, error) {
+	return g.Cmd("HGETALL", key).releaseHash()
+}
+
+// 删除一个或多个field，返回实际删除的数量
+func (g *Gedis)HDel(key string, fields... string) (int64, error) {
+	return g.Cmd("HDEL", key, fields).Int64()
+}
+
+func (g *Gedis)HExists(key, field string) (bool, error) {
+	return g.Cmd("HEXISTS", key, field).Bool()
+}
+
+func (g *Gedis)HIncrBy(key, field string, increment int64) (int64, error) {
+	return g.Cmd("HINCRBY", key, field, increment).Int64()
+}
+
+func (g *Gedis)HIncrByFloat(key, field string, increment float64) (float64, error) {
+	return g.Cmd("HINCRBYFLOAT", key, field, increment).Float64()
+}
+
+func (g *Gedis)HKeys(key string) ([]string, error) {
+	return g.Cmd("HKEYS", key).releaseList()
+}
+
+func (g *Gedis)HVals(key string) ([]string, error) {
+	return g.Cmd("HVALS", key).releaseList()
+}
+
+func (g *Gedis)HLen(key string) (int64, error) {
+	return g.Cmd("HLEN", key).Int64()
+}
+
+// 只在field不存在时设置，返回是否设置成功
+func (g *Gedis)HSetNX(key, field string, value interface{}) (bool, error) {
+	return g.Cmd("HSETNX", key, field, value).Bool()
+}
+
+func (g *Gedis)HStrLen(key, field string) (int64, error) {
+	return g.Cmd("HSTRLEN", key, field).Int64()
+}
+
+// 随机返回一个field，key不存在时返回ErrNil
+func (g *Gedis)HRandField(key string) (string, error) {
+	return g.Cmd("HRANDFIELD", key).Str()
+}
+
+// 随机返回count个field，count为负数时允许重复
+func (g *Gedis)HRandFields(key string, count int64) ([]string, error) {
+	return g.Cmd("HRANDFIELD", key, count).releaseList()
+}
+
+// field及其值
+type HashField struct {
+	Field string
+	Value string
+}
+
+// 随机返回count个field及其值，count为负数时允许重复，所以不使用map返回
+func (g *Gedis)HRandFieldsWithValues(key string, count int64) ([]HashField, error) {
+	list, err := g.Cmd("HRANDFIELD", key, count, "WITHVALUES").releaseList()
+	if err != nil {
+		return nil, err
+	}
+	if len(list) % 2 != 0 {
+		return nil, errors.New("reply has odd number of children")
+	}
+	fields := make([]HashField, len(list) / 2)
+	for i := range fields {
+		fields[i] = HashField{Field: list[i * 2], Value: list[i * 2 + 1]}
+	}
+	return fields, nil
+}
+
+// EXPIRE/HEXPIRE等命令的NX/XX/GT/LT条件，空值表示不带条件
+type ExpireCondition string
+
+const (
+	ExpireAlways ExpireCondition = ""
+	ExpireNX     ExpireCondition = "NX" // 当前没有过期时间时才设置
+	ExpireXX     ExpireCondition = "XX" // 当前已有过期时间时才设置
+	ExpireGT     ExpireCondition = "GT" // 新的过期时间大于当前值时才设置
+	ExpireLT     ExpireCondition = "LT" // 新的过期时间小于当前值时才设置
+)
+
+// HEXPIRE/HPERSIST/HTTL 等field级过期命令对每个field返回的状态码
+type HashFieldStatus int64
+
+const (
+	HashFieldMissing         HashFieldStatus = -2 // field或key不存在
+	HashFieldNoExpire        HashFieldStatus = -1 // field没有设置过期时间(HPERSIST/HTTL)
+	HashFieldConditionNotMet HashFieldStatus = 0  // NX/XX/GT/LT条件不满足(HEXPIRE)
+	HashFieldOK              HashFieldStatus = 1  // 过期时间设置成功或移除成功
+	HashFieldDeleted         HashFieldStatus = 2  // 过期时间为0或已经过去，field被直接删除(HEXPIRE)
+)
+
+// HTTL/HPTTL 的返回值，只有Status为HashFieldOK时TTL才有意义
+type HashFieldTTL struct {
+	Status HashFieldStatus
+	TTL    time.Duration
+}
+
+// 设置field的过期时间(秒级，不是整秒时按毫秒发送HPEXPIRE)，返回值与fields一一对应
+func (g *Gedis)HExpire(key string, ttl time.Duration, cond ExpireCondition, fields... string) ([]HashFieldStatus, error) {
+	cmd, n := ttlArg("HEXPIRE", "HPEXPIRE", ttl)
+	return hashFieldStatuses(g.Cmd(cmd, key, n, hashFieldArgs(cond, fields)))
+}
+
+// 设置field的过期时间(毫秒级)，返回值与fields一一对应
+func (g *Gedis)HPExpire(key string, ttl time.Duration, cond ExpireCondition, fields... string) ([]HashFieldStatus, error) {
+	return hashFieldStatuses(g.Cmd("HPEXPIRE", key, milliseconds(ttl), hashFieldArgs(cond, fields)))
+}
+
+// 移除field的过期时间，返回值与fields一一对应
+func (g *Gedis)HPersist(key string, fields... string) ([]HashFieldStatus, error) {
+	return hashFieldStatuses(g.Cmd("HPERSIST", key, hashFieldArgs(ExpireAlways, fields)))
+}
+
+// 返回field的剩余过期时间(秒级)
+func (g *Gedis)HTTL(key string, fields... string) ([]HashFieldTTL, error) {
+	return hashFieldTTLs(g.Cmd("HTTL", key, hashFieldArgs(ExpireAlways, fields)), time.Second)
+}
+
+// 返回field的剩余过期时间(毫秒级)
+func (g *Gedis)HPTTL(key string, fields... string) ([]HashFieldTTL, error) {
+	return hashFieldTTLs(g.Cmd("HPTTL", key, hashFieldArgs(ExpireAlways, fields)), time.Millisecond)
+}
+
+// 组装 [NX|XX|GT|LT] FIELDS numfields field [field ...]
+func hashFieldArgs(cond ExpireCondition, fields []string) []interface{} {
+	args := make([]interface{}, 0, len(fields) + 3)
+	if cond != ExpireAlways {
+		args = append(args, string(cond))
+	}
+	args = append(args, "FIELDS", len(fields))
+	for _, f := range fields {
+		args = append(args, f)
+	}
+	return args
+}
+
+func hashFieldStatuses(r *Reply) ([]HashFieldStatus, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	statuses := make([]HashFieldStatus, len(r.Children))
+	for i, c := range r.Children {
+		code, err := c.Int64()
+		if err != nil {
+			return nil, err
+		}
+		statuses[i] = HashFieldStatus(code)
+	}
+	return statuses, nil
+}
+
+func hashFieldTTLs(r *Reply, unit time.Duration) ([]HashFieldTTL, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	ttls := make([]HashFieldTTL, len(r.Children))
+	for i, c := range r.Children {
+		n, err := c.Int64()
+		if err != nil {
+			return nil, err
+		}
+		switch n {
+		case -2:
+			ttls[i].Status = HashFieldMissing
+		case -1:
+			ttls[i].Status = HashFieldNoExpire
+		default:
+			ttls[i] = HashFieldTTL{Status: HashFieldOK, TTL: time.Duration(n) * unit}
+		}
+	}
+	return ttls, nil
+}
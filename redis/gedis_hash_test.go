@@ -0,0 +1,97 @@
+package gedis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHExpireUnit(t *testing.T) {
+	tests := []struct {
+		ttl     time.Duration
+		cond    ExpireCondition
+		pexpire bool
+		want    string
+	}{
+		{10 * time.Second, ExpireAlways, false, respCommand("HEXPIRE", "k", "10", "FIELDS", "1", "f")},
+		{1500 * time.Millisecond, ExpireNX, false, respCommand("HPEXPIRE", "k", "1500", "NX", "FIELDS", "1", "f")},
+		{2 * time.Second, ExpireGT, true, respCommand("HPEXPIRE", "k", "2000", "GT", "FIELDS", "1", "f")},
+		{200 * time.Microsecond, ExpireAlways, true, respCommand("HPEXPIRE", "k", "1", "FIELDS", "1", "f")},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis(respArray(":1\r\n"))
+		var err error
+		if tt.pexpire {
+			_, err = g.HPExpire("k", tt.ttl, tt.cond, "f")
+		} else {
+			_, err = g.HExpire("k", tt.ttl, tt.cond, "f")
+		}
+		if err != nil {
+			t.Fatalf("ttl %v: %v", tt.ttl, err)
+		}
+		if fc.written.String() != tt.want {
+			t.Errorf("ttl %v: written %q, want %q", tt.ttl, fc.written.String(), tt.want)
+		}
+	}
+}
+
+func TestHashFieldStatuses(t *testing.T) {
+	g, _ := newFakeGedis(respArray(":-2\r\n", ":0\r\n", ":1\r\n", ":2\r\n"))
+	statuses, err := g.HExpire("k", time.Second, ExpireAlways, "a", "b", "c", "d")
+	if err != nil {
+		t.Fatalf("HExpire: %v", err)
+	}
+	want := []HashFieldStatus{HashFieldMissing, HashFieldConditionNotMet, HashFieldOK, HashFieldDeleted}
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("HExpire = %v, want %v", statuses, want)
+	}
+
+	g, _ = newFakeGedis(respArray(":-1\r\n", ":1\r\n"))
+	statuses, err = g.HPersist("k", "a", "b")
+	if err != nil || !reflect.DeepEqual(statuses, []HashFieldStatus{HashFieldNoExpire, HashFieldOK}) {
+		t.Errorf("HPersist = %v, %v", statuses, err)
+	}
+
+	g, _ = newFakeGedis("-ERR wrong type\r\n")
+	if statuses, err := g.HPersist("k", "a"); statuses != nil || err == nil {
+		t.Errorf("HPersist = %v, %v, want the error reply", statuses, err)
+	}
+}
+
+func TestHashFieldTTLs(t *testing.T) {
+	want := func(unit time.Duration) []HashFieldTTL {
+		return []HashFieldTTL{
+			{Status: HashFieldMissing},
+			{Status: HashFieldNoExpire},
+			{Status: HashFieldOK, TTL: 30 * unit},
+		}
+	}
+	g, fc := newFakeGedis(respArray(":-2\r\n", ":-1\r\n", ":30\r\n"))
+	ttls, err := g.HTTL("k", "a", "b", "c")
+	if err != nil || !reflect.DeepEqual(ttls, want(time.Second)) {
+		t.Errorf("HTTL = %+v, %v", ttls, err)
+	}
+	if w := respCommand("HTTL", "k", "FIELDS", "3", "a", "b", "c"); fc.written.String() != w {
+		t.Errorf("written = %q, want %q", fc.written.String(), w)
+	}
+
+	g, _ = newFakeGedis(respArray(":-2\r\n", ":-1\r\n", ":30\r\n"))
+	ttls, err = g.HPTTL("k", "a", "b", "c")
+	if err != nil || !reflect.DeepEqual(ttls, want(time.Millisecond)) {
+		t.Errorf("HPTTL = %+v, %v", ttls, err)
+	}
+}
+
+func TestHRandFieldsWithValues(t *testing.T) {
+	g, _ := newFakeGedis(respArray(respBulk("a"), respBulk("1"), respBulk("a"), respBulk("1")))
+	fields, err := g.HRandFieldsWithValues("k", -2)
+	want := []HashField{{"a", "1"}, {"a", "1"}}
+	if err != nil || !reflect.DeepEqual(fields, want) {
+		t.Errorf("HRandFieldsWithValues = %v, %v, want %v", fields, err, want)
+	}
+
+	g, _ = newFakeGedis(respArray(respBulk("a")))
+	if _, err := g.HRandFieldsWithValues("k", 1); err == nil {
+		t.Error("odd number of children was accepted")
+	}
+}
@@ -0,0 +1,313 @@
+package gedis
+
+import "time"
+
+// Key空间管理相关的命令
+
+// 返回存在的key的数量，同一个key出现多次时重复计数
+func (g *Gedis)Exists(keys... string) (int64, error) {
+	return g.Cmd("EXISTS", keys).Int64()
+}
+
+// 返回key的类型，key不存在时返回"none"
+func (g *Gedis)Type(key string) (string, error) {
+	return g.Cmd("TYPE", key).Str()
+}
+
+// 设置过期时间(秒级，不是整秒时按毫秒发送PEXPIRE)，返回是否设置成功(key不存在或条件不满足时为false)
+func (g *Gedis)Expire(key string, ttl time.Duration, cond ExpireCondition) (bool, error) {
+	cmd, n := ttlArg("EXPIRE", "PEXPIRE", ttl)
+	return g.Cmd(cmd, key, n, expireCondArgs(cond)).Bool()
+}
+
+// 设置过期时间(毫秒级)，不足1毫秒的按1毫秒发送
+func (g *Gedis)PExpire(key string, ttl time.Duration, cond ExpireCondition) (bool, error) {
+	return g.Cmd("PEXPIRE", key, milliseconds(ttl), expireCondArgs(cond)).Bool()
+}
+
+// 设置过期时间点(秒级)
+func (g *Gedis)ExpireAt(key string, at time.Time, cond ExpireCondition) (bool, error) {
+	return g.Cmd("EXPIREAT", key, at.Unix(), expireCondArgs(cond)).Bool()
+}
+
+// 设置过期时间点(毫秒级)
+func (g *Gedis)PExpireAt(key string, at time.Time, cond ExpireCondition) (bool, error) {
+	return g.Cmd("PEXPIREAT", key, at.UnixMilli(), expireCondArgs(cond)).Bool()
+}
+
+func expireCondArgs(cond ExpireCondition) []interface{} {
+	if cond == ExpireAlways {
+		return nil
+	}
+	return []interface{}{string(cond)}
+}
+
+// key的过期状态，对应TTL等命令返回的 -2、-1 及正常值
+type KeyExpireState int8
+
+const (
+	KeyVolatile   KeyExpireState = iota // key存在且设置了过期时间
+	KeyPersistent                       // key存在但没有设置过期时间(-1)
+	KeyMissing                          // key不存在(-2)
+)
+
+// TTL/PTTL的返回值，只有State为KeyVolatile时TTL才有意义
+type KeyTTL struct {
+	State KeyExpireState
+	TTL   time.Duration
+}
+
+// EXPIRETIME/PEXPIRETIME的返回值，只有State为KeyVolatile时At才有意义
+type KeyExpireTime struct {
+	State KeyExpireState
+	At    time.Time
+}
+
+func (g *Gedis)TTL(key string) (KeyTTL, error) {
+	return parseKeyTTL(g.Cmd("TTL", key), time.Second)
+}
+
+func (g *Gedis)PTTL(key string) (KeyTTL, error) {
+	return parseKeyTTL(g.Cmd("PTTL", key), time.Millisecond)
+}
+
+// 返回key的过期时间点(秒级)
+func (g *Gedis)ExpireTime(key string) (KeyExpireTime, error) {
+	n, state, err := parseExpireState(g.Cmd("EXPIRETIME", key))
+	if err != nil || state != KeyVolatile {
+		return KeyExpireTime{State: state}, err
+	}
+	return KeyExpireTime{State: state, At: time.Unix(n, 0)}, nil
+}
+
+// 返回key的过期时间点(毫秒级)
+func (g *Gedis)PExpireTime(key string) (KeyExpireTime, error) {
+	n, state, err := parseExpireState(g.Cmd("PEXPIRETIME", key))
+	if err != nil || state != KeyVolatile {
+		return KeyExpireTime{State: state}, err
+	}
+	return KeyExpireTime{State: state, At: time.UnixMilli(n)}, nil
+}
+
+func parseKeyTTL(r *Reply, unit time.Duration) (KeyTTL, error) {
+	n, state, err := parseExpireState(r)
+	if err != nil || state != KeyVolatile {
+		return KeyTTL{State: state}, err
+	}
+	return KeyTTL{State: state, TTL: time.Duration(n) * unit}, nil
+}
+
+// 将 -2、-1 映射为KeyMissing、KeyPersistent
+func parseExpireState(r *Reply) (int64, KeyExpireState, error) {
+	n, err := r.Int64()
+	if err != nil {
+		return 0, KeyMissing, err
+	}
+	switch n {
+	case -2:
+		return n, KeyMissing, nil
+	case -1:
+		return n, KeyPersistent, nil
+	}
+	return n, KeyVolatile, nil
+}
+
+// 移除过期时间，返回是否移除成功
+func (g *Gedis)Persist(key string) (bool, error) {
+	return g.Cmd("PERSIST", key).Bool()
+}
+
+// 成功后返回"OK"
+func (g *Gedis)Rename(key, newKey string) (string, error) {
+	return g.Cmd("RENAME", key, newKey).Str()
+}
+
+// 只在newKey不存在时重命名，返回是否重命名成功
+func (g *Gedis)RenameNX(key, newKey string) (bool, error) {
+	return g.Cmd("RENAMENX", key, newKey).Bool()
+}
+
+// 在当前db内复制key，返回是否复制成功
+func (g *Gedis)Copy(source, destination string, replace bool) (bool, error) {
+	return g.CopyToDB(source, destination, -1, replace)
+}
+
+// 复制key到指定db，db为负数时表示当前db
+func (g *Gedis)CopyToDB(source, destination string, db int, replace bool) (bool, error) {
+	args := []interface{}{source, destination}
+	if db >= 0 {
+		args = append(args, "DB", db)
+	}
+	if replace {
+		args = append(args, "REPLACE")
+	}
+	return g.Cmd("COPY", args...).Bool()
+}
+
+// 异步删除key，返回删除的数量
+func (g *Gedis)Unlink(keys... string) (int64, error) {
+	return g.Cmd("UNLINK", keys).Int64()
+}
+
+// 更新key的最后访问时间，返回存在的key的数量
+func (g *Gedis)Touch(keys... string) (int64, error) {
+	return g.Cmd("TOUCH", keys).Int64()
+}
+
+// 随机返回一个key，db为空时返回ErrNil
+func (g *Gedis)RandomKey() (string, error) {
+	return g.Cmd("RANDOMKEY").Str()
+}
+
+// 将key移动到指定db，返回是否移动成功
+func (g *Gedis)Move(key string, db int) (bool, error) {
+	return g.Cmd("MOVE", key, db).Bool()
+}
+
+// 返回key内部的编码方式，key不存在时返回ErrNil
+func (g *Gedis)ObjectEncoding(key string) (string, error) {
+	return g.Cmd("OBJECT", "ENCODING", key).Str()
+}
+
+// 返回key的访问频率，只在maxmemory-policy为LFU时可用
+func (g *Gedis)ObjectFreq(key string) (int64, error) {
+	return g.Cmd("OBJECT", "FREQ", key).Int64()
+}
+
+// 返回key的空闲时间，只在maxmemory-policy不为LFU时可用
+func (g *Gedis)ObjectIdleTime(key string) (time.Duration, error) {
+	n, err := g.Cmd("OBJECT", "IDLETIME", key).Int64()
+	return time.Duration(n) * time.Second, err
+}
+
+func (g *Gedis)ObjectRefCount(key string) (int64, error) {
+	return g.Cmd("OBJECT", "REFCOUNT", key).Int64()
+}
+
+// 返回key序列化后的值，key不存在时返回ErrNil
+func (g *Gedis)Dump(key string) ([]byte, error) {
+	return g.Cmd("DUMP", key).Bytes()
+}
+
+// RESTORE命令的可选参数
+type RestoreOptions struct {
+	Replace  bool
+	// 不为零值时使用ABSTTL，以该时间点作为过期时间，忽略Restore的ttl参数
+	ExpireAt time.Time
+	// 大于0时发送IDLETIME/FREQ，分别用于LRU和LFU
+	IdleTime time.Duration
+	Freq     int64
+}
+
+// 使用DUMP得到的值恢复key，ttl为0表示不过期，成功后返回"OK"
+func (g *Gedis)Restore(key string, ttl time.Duration, value []byte, opt *RestoreOptions) (string, error) {
+	if opt == nil {
+		return g.Cmd("RESTORE", key, int64(ttl / time.Millisecond), value).Str()
+	}
+	args := make([]interface{}, 0, 10)
+	args = append(args, key)
+	if !opt.ExpireAt.IsZero() {
+		args = append(args, opt.ExpireAt.UnixMilli())
+	} else {
+		args = append(args, int64(ttl / time.Millisecond))
+	}
+	args = append(args, value)
+	if opt.Replace {
+		args = append(args, "REPLACE")
+	}
+	if !opt.ExpireAt.IsZero() {
+		args = append(args, "ABSTTL")
+	}
+	if opt.IdleTime > 0 {
+		args = append(args, "IDLETIME", int64(opt.IdleTime / time.Second))
+	}
+	if opt.Freq > 0 {
+		args = append(args, "FREQ", opt.Freq)
+	}
+	return g.Cmd("RESTORE", args...).Str()
+}
+
+// SORT/SORT_RO的参数构造器:
+//
+//	s := NewSort("ids").By("weight_*").Get("#", "name_*").Limit(0, 10).Desc().Alpha()
+//	list, err := gedis.Sort(s)
+type SortBuilder struct {
+	key    string
+	by     string
+	limit  bool
+	offset int64
+	count  int64
+	gets   []string
+	desc   bool
+	alpha  bool
+}
+
+func NewSort(key string) *SortBuilder {
+	return &SortBuilder{key: key}
+}
+
+// 使用外部key的值排序，"nosort"表示不排序
+func (s *SortBuilder)By(pattern string) *SortBuilder {
+	s.by = pattern
+	return s
+}
+
+func (s *SortBuilder)Limit(offset, count int64) *SortBuilder {
+	s.limit = true
+	s.offset = offset
+	s.count = count
+	return s
+}
+
+// 返回外部key的值，"#"表示元素本身，可多次调用
+func (s *SortBuilder)Get(patterns... string) *SortBuilder {
+	s.gets = append(s.gets, patterns...)
+	return s
+}
+
+func (s *SortBuilder)Desc() *SortBuilder {
+	s.desc = true
+	return s
+}
+
+// 按字典序排序
+func (s *SortBuilder)Alpha() *SortBuilder {
+	s.alpha = true
+	return s
+}
+
+func (s *SortBuilder)args() []interface{} {
+	args := make([]interface{}, 0, len(s.gets) * 2 + 8)
+	args = append(args, s.key)
+	if s.by != "" {
+		args = append(args, "BY", s.by)
+	}
+	if s.limit {
+		args = append(args, "LIMIT", s.offset, s.count)
+	}
+	for _, p := range s.gets {
+		args = append(args, "GET", p)
+	}
+	if s.desc {
+		args = append(args, "DESC")
+	}
+	if s.alpha {
+		args = append(args, "ALPHA")
+	}
+	return args
+}
+
+// GET的外部key不存在时，对应的元素为""
+func (g *Gedis)Sort(s *SortBuilder) ([]string, error) {
+	return g.Cmd("SORT", s.args()).releaseList()
+}
+
+// 只读版本的SORT，可以在只读副本上执行
+func (g *Gedis)SortRO(s *SortBuilder) ([]string, error) {
+	return g.Cmd("SORT_RO", s.args()).releaseList()
+}
+
+// 将结果保存到destination，返回结果的元素数
+func (g *Gedis)SortStore(s *SortBuilder, destination string) (int64, error) {
+	return g.Cmd("SORT", s.args(), "STORE", destination).Int64()
+}
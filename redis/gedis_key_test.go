@@ -0,0 +1,36 @@
+package gedis
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExpireUnit(t *testing.T) {
+	tests := []struct {
+		ttl     time.Duration
+		pexpire bool
+		want    string
+	}{
+		{10 * time.Second, false, "*3\r\n$6\r\nEXPIRE\r\n$1\r\nk\r\n$2\r\n10\r\n"},
+		{500 * time.Millisecond, false, "*3\r\n$7\r\nPEXPIRE\r\n$1\r\nk\r\n$3\r\n500\r\n"},
+		{1500 * time.Millisecond, false, "*3\r\n$7\r\nPEXPIRE\r\n$1\r\nk\r\n$4\r\n1500\r\n"},
+		{200 * time.Microsecond, true, "*3\r\n$7\r\nPEXPIRE\r\n$1\r\nk\r\n$1\r\n1\r\n"},
+		{20 * time.Millisecond, true, "*3\r\n$7\r\nPEXPIRE\r\n$1\r\nk\r\n$2\r\n20\r\n"},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis(":1\r\n")
+		var ok bool
+		var err error
+		if tt.pexpire {
+			ok, err = g.PExpire("k", tt.ttl, ExpireAlways)
+		} else {
+			ok, err = g.Expire("k", tt.ttl, ExpireAlways)
+		}
+		if err != nil || !ok {
+			t.Fatalf("ttl %v: %v %v", tt.ttl, ok, err)
+		}
+		if fc.written.String() != tt.want {
+			t.Errorf("ttl %v: written %q, want %q", tt.ttl, fc.written.String(), tt.want)
+		}
+	}
+}
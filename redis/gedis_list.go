@@ -0,0 +1,225 @@
+package gedis
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// List类型相关的命令
+
+// LMOVE/LMPOP等命令中表示从列表的哪一端操作
+type ListDirection string
+
+const (
+	Left  ListDirection = "LEFT"
+	Right ListDirection = "RIGHT"
+)
+
+// 返回push之后列表的长度
+func (g *Gedis)LPush(key string, values... interface{}) (int64, error) {
+	return g.Cmd("LPUSH", key, values).Int64()
+}
+
+func (g *Gedis)RPush(key string, values... interface{}) (int64, error) {
+	return g.Cmd("RPUSH", key, values).Int64()
+}
+
+// 只在key存在时push
+func (g *Gedis)LPushX(key string, values... interface{}) (int64, error) {
+	return g.Cmd("LPUSHX", key, values).Int64()
+}
+
+func (g *Gedis)RPushX(key string, values... interface{}) (int64, error) {
+	return g.Cmd("RPUSHX", key, values).Int64()
+}
+
+// 列表为空时返回ErrNil
+func (g *Gedis)LPop(key string) (string, error) {
+	return g.Cmd("LPOP", key).Str()
+}
+
+// 弹出最多count个元素，列表为空时返回ErrNil
+func (g *Gedis)LPopCount(key string, count int64) ([]string, error) {
+	return nilAsErrNil(g.Cmd("LPOP", key, count)).releaseList()
+}
+
+func (g *Gedis)RPop(key string) (string, error) {
+	return g.Cmd("RPOP", key).Str()
+}
+
+func (g *Gedis)RPopCount(key string, count int64) ([]string, error) {
+	return nilAsErrNil(g.Cmd("RPOP", key, count)).releaseList()
+}
+
+func (g *Gedis)LLen(key string) (int64, error) {
+	return g.Cmd("LLEN", key).Int64()
+}
+
+func (g *Gedis)LRange(key string, start, stop int64) ([]string, error) {
+	return g.Cmd("LRANGE", key, start, stop).releaseList()
+}
+
+// 下标越界时返回ErrNil
+func (g *Gedis)LIndex(key string, index int64) (string, error) {
+	return g.Cmd("LINDEX", key, index).Str()
+}
+
+// 在pivot之前插入value，返回插入后列表的长度，pivot不存在时返回-1
+func (g *Gedis)LInsertBefore(key string, pivot, value interface{}) (int64, error) {
+	return g.Cmd("LINSERT", key, "BEFORE", pivot, value).Int64()
+}
+
+// 在pivot之后插入value，返回插入后列表的长度，pivot不存在时返回-1
+func (g *Gedis)LInsertAfter(key string, pivot, value interface{}) (int64, error) {
+	return g.Cmd("LINSERT", key, "AFTER", pivot, value).Int64()
+}
+
+// 成功后返回"OK"
+func (g *Gedis)LSet(key string, index int64, value interface{}) (string, error) {
+	return g.Cmd("LSET", key, index, value).Str()
+}
+
+// 移除count个值为value的元素，count为0时移除全部，返回移除的数量
+func (g *Gedis)LRem(key string, count int64, value interface{}) (int64, error) {
+	return g.Cmd("LREM", key, count, value).Int64()
+}
+
+// 成功后返回"OK"
+func (g *Gedis)LTrim(key string, start, stop int64) (string, error) {
+	return g.Cmd("LTRIM", key, start, stop).Str()
+}
+
+// LPOS命令的可选参数，值为0的参数不发送
+type LPosOptions struct {
+	Rank   int64
+	MaxLen int64
+}
+
+func (o *LPosOptions) args() []interface{} {
+	args := make([]interface{}, 0, 4)
+	if o == nil {
+		return args
+	}
+	if o.Rank != 0 {
+		args = append(args, "RANK", o.Rank)
+	}
+	if o.MaxLen != 0 {
+		args = append(args, "MAXLEN", o.MaxLen)
+	}
+	return args
+}
+
+// 返回value在列表中的下标，不存在时返回ErrNil
+func (g *Gedis)LPos(key string, value interface{}, opt *LPosOptions) (int64, error) {
+	return g.Cmd("LPOS", key, value, opt.args()).Int64()
+}
+
+// 返回最多count个匹配value的下标，count为0时返回全部
+func (g *Gedis)LPosCount(key string, value interface{}, count int64, opt *LPosOptions) ([]int64, error) {
+	return intList(g.Cmd("LPOS", key, value, "COUNT", count, opt.args()))
+}
+
+// 从source的from端弹出一个元素push到destination的to端，source为空时返回ErrNil
+func (g *Gedis)LMove(source, destination string, from, to ListDirection) (string, error) {
+	return g.Cmd("LMOVE", source, destination, string(from), string(to)).Str()
+}
+
+// 从第一个非空的列表中弹出最多count个元素，返回该列表的key和弹出的元素，所有列表都为空时返回ErrNil
+func (g *Gedis)LMPop(direction ListDirection, count int64, keys... string) (string, []string, error) {
+	return parseKeyValues(g.Cmd("LMPOP", len(keys), keys, string(direction), "COUNT", count))
+}
+
+// 阻塞版本的LPOP，返回弹出元素所在列表的key和元素值；
+// timeout为0表示一直阻塞，超时后返回ErrNil
+func (g *Gedis)BLPop(timeout time.Duration, keys... string) (string, string, error) {
+	return parseKeyValue(g.BlockingCmd(timeout, "BLPOP", keys, formatTimeout(timeout)))
+}
+
+func (g *Gedis)BRPop(timeout time.Duration, keys... string) (string, string, error) {
+	return parseKeyValue(g.BlockingCmd(timeout, "BRPOP", keys, formatTimeout(timeout)))
+}
+
+// 阻塞版本的LMOVE，超时后返回ErrNil
+func (g *Gedis)BLMove(source, destination string, from, to ListDirection, timeout time.Duration) (string, error) {
+	return g.BlockingCmd(timeout, "BLMOVE", source, destination, string(from), string(to), formatTimeout(timeout)).Str()
+}
+
+// 阻塞版本的LMPOP，超时后返回ErrNil
+func (g *Gedis)BLMPop(timeout time.Duration, direction ListDirection, count int64, keys... string) (string, []string, error) {
+	return parseKeyValues(g.BlockingCmd(timeout, "BLMPOP", formatTimeout(timeout), len(keys), keys, string(direction), "COUNT", count))
+}
+
+// 将阻塞时长转换成以秒为单位的小数，Redis 6.0之后支持小数形式的timeout；
+// 服务端的精度为毫秒，不足1毫秒的timeout按1毫秒发送，避免变成0(一直阻塞)
+func formatTimeout(timeout time.Duration) string {
+	return strconv.FormatFloat(float64(milliseconds(timeout)) / 1000, 'f', -1, 64)
+}
+
+// 部分命令在没有结果时返回nil数组，将其转换为ErrNil
+func nilAsErrNil(r *Reply) *Reply {
+	if r.Type == NilReply {
+		return &Reply{Type: ErrorReply, Err: ErrNil}
+	}
+	return r
+}
+
+// 解析 [key, value] 形式的返回值
+func parseKeyValue(r *Reply) (string, string, error) {
+	if r.Type == QueuedReply {
+		return "", "", nil
+	}
+	list, err := nilAsErrNil(r).List()
+	if err != nil {
+		return "", "", err
+	}
+	if len(list) != 2 {
+		return "", "", errors.New("reply is not formatted as a key value pair")
+	}
+	return list[0], list[1], nil
+}
+
+// 解析 [key, [value ...]] 形式的返回值
+func parseKeyValues(r *Reply) (string, []string, error) {
+	r = nilAsErrNil(r)
+	if r.Type == ErrorReply {
+		return "", nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return "", nil, nil
+	}
+	if r.Type != MultiReply || len(r.Children) != 2 {
+		return "", nil, errors.New("reply is not formatted as a key values pair")
+	}
+	key, err := r.Children[0].Str()
+	if err != nil {
+		return "", nil, err
+	}
+	values, err := r.Children[1].List()
+	if err != nil {
+		return "", nil, err
+	}
+	return key, values, nil
+}
+
+// 将元素都为整数的MultiReply转换成[]int64
+func intList(r *Reply) ([]int64, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	list := make([]int64, len(r.Children))
+	for i, c := range r.Children {
+		n, err := c.Int64()
+		if err != nil {
+			return nil, err
+		}
+		list[i] = n
+	}
+	return list, nil
+}
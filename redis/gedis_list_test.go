@@ -0,0 +1,82 @@
+package gedis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFormatTimeout(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    string
+	}{
+		{0, "0"},
+		{2 * time.Second, "2"},
+		{1500 * time.Millisecond, "1.5"},
+		{10 * time.Millisecond, "0.01"},
+		{300 * time.Microsecond, "0.001"},
+	}
+	for _, tt := range tests {
+		if got := formatTimeout(tt.timeout); got != tt.want {
+			t.Errorf("formatTimeout(%v) = %q, want %q", tt.timeout, got, tt.want)
+		}
+	}
+}
+
+// 阻塞命令的读超时在连接超时的基础上延长阻塞时长，阻塞时长为0时取消读超时
+func TestBlockingReadDeadline(t *testing.T) {
+	const connTimeout = time.Second
+	tests := []struct {
+		name string
+		call func(g *Gedis, timeout time.Duration) error
+	}{
+		{"BLPOP", func(g *Gedis, timeout time.Duration) error { _, _, err := g.BLPop(timeout, "q"); return err }},
+		{"BRPOP", func(g *Gedis, timeout time.Duration) error { _, _, err := g.BRPop(timeout, "q"); return err }},
+		{"BLMOVE", func(g *Gedis, timeout time.Duration) error {
+			_, err := g.BLMove("a", "b", Left, Right, timeout)
+			return err
+		}},
+		{"BLMPOP", func(g *Gedis, timeout time.Duration) error {
+			_, _, err := g.BLMPop(timeout, Left, 1, "q")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis("*-1\r\n", "*-1\r\n")
+		g.conn.timeout = connTimeout
+		start := time.Now()
+		// 超时返回nil，转换为ErrNil
+		if err := tt.call(g, 2*time.Second); err != ErrNil {
+			t.Errorf("%s: err = %v, want ErrNil", tt.name, err)
+		}
+		if d := fc.readDeadline.Sub(start); d < 3*time.Second || d > 4*time.Second {
+			t.Errorf("%s: read deadline %v after start, want connection timeout plus block", tt.name, d)
+		}
+		if err := tt.call(g, 0); err != ErrNil {
+			t.Errorf("%s: err = %v, want ErrNil", tt.name, err)
+		}
+		if !fc.readDeadline.IsZero() {
+			t.Errorf("%s: read deadline %v, want none for timeout 0", tt.name, fc.readDeadline)
+		}
+	}
+}
+
+func TestBLPopCommand(t *testing.T) {
+	g, fc := newFakeGedis(respArray(respBulk("q"), respBulk("v")))
+	k, v, err := g.BLPop(300*time.Microsecond, "q")
+	if err != nil || k != "q" || v != "v" {
+		t.Fatalf("BLPop = %q, %q, %v", k, v, err)
+	}
+	if w := respCommand("BLPOP", "q", "0.001"); fc.written.String() != w {
+		t.Errorf("written = %q, want %q", fc.written.String(), w)
+	}
+}
+
+func TestBLMPopReply(t *testing.T) {
+	g, _ := newFakeGedis(respArray(respBulk("q"), respArray(respBulk("a"), respBulk("b"))))
+	k, values, err := g.BLMPop(time.Second, Left, 2, "p", "q")
+	if err != nil || k != "q" || !reflect.DeepEqual(values, []string{"a", "b"}) {
+		t.Errorf("BLMPop = %q, %q, %v", k, values, err)
+	}
+}
@@ -1,12 +1,14 @@
 package gedis
 
+import "time"
+
 type GedisPool struct {
 	host    string
 
 	port    int
 
-	// 用一个chan来存放连接对象
-	pool    chan *Gedis
+	// 存放连接对象，并限制连接总数
+	pool    *boundedPool[*Gedis]
 
 	// 自定义连接创建方法，默认使用Dial(host,port)
 	//
@@ -15,74 +17,87 @@ type GedisPool struct {
 
 type PoolBuilder func(host string, port int) (*Gedis, error)
 
+// 最多size个连接，参见DefaultPoolConfig
 func NewGedisPool(host string, port, size int) (*GedisPool, error) {
-	return NewGedisPoolWithCustom(host, port, size, NewGedis(host, port))
+	return NewGedisPoolWithCustom(host, port, size, NewGedis)
 }
 
 func NewGedisPoolWithCustom(host string, port, size int, builder PoolBuilder) (*GedisPool, error) {
-	gedises := make([]*Gedis, 0, size)
-	for i := 0; i < size; i++ {
-		gedis, err := builder(host, port)
-		if err != nil {
-			// 如果有一个连接创建失败，则关闭其它所有已经创建的连接，然后返回nil
-			for _, g := range gedises {
-				g.Close()
-			}
-			return nil, err
-		}
-		if gedis != nil {
-			gedises = append(gedises, gedis)
-		}
+	return NewGedisPoolWithConfig(host, port, DefaultPoolConfig(size), builder)
+}
+
+// builder为nil时使用NewGedis
+func NewGedisPoolWithConfig(host string, port int, config PoolConfig, builder PoolBuilder) (*GedisPool, error) {
+	if builder == nil {
+		builder = NewGedis
 	}
-	p := GedisPool{
+	p := &GedisPool{
 		host: host,
 		port : port,
-		pool: make(chan *Gedis, len(gedises)),
 		builder: builder,
 	}
-	for i := range gedises {
-		p.pool <- gedises[i]
+	pool, err := newBoundedPool(config, func() (*Gedis, error) {
+		return builder(host, port)
+	}, closeGedis, pingGedis)
+	if err != nil {
+		return nil, err
 	}
-	return &p, nil
+	p.pool = pool
+	return p, nil
 }
 
-// 从连接池中获取连接对象
+// 从连接池中获取连接对象，连接数达到MaxActive时最多等待MaxWait，仍没有可用连接时返回ErrPoolExhausted
 func (p *GedisPool) Get() (*Gedis, error) {
-	select {
-	case g := <-p.pool:
-	// 如果是从pool中获取的Gedis，则设置其pool属性
-		g.Pool = p
-		return g, nil
-	default:
-	// TODO 此处没有想清楚，所以直接创建一个连接返回
-		return p.builder(p.host, p.port), nil
+	g, err := p.pool.get()
+	if err != nil {
+		return nil, err
 	}
+	// 如果是从pool中获取的Gedis，则设置其pool属性
+	g.Pool = p
+	return g, nil
 }
 
+// 已关闭的连接(读写出错、Monitor.Stop等)不再放回，直接丢弃并释放其占用的位置
 func (p *GedisPool)Put(g *Gedis) {
-	select {
-	case p.pool <- g:
-	default:
-		g.Close()
+	if g.conn.isClosed() {
+		p.pool.discard(g)
+		return
 	}
+	p.pool.put(g)
 }
 
+// 关闭所有空闲的连接
 func (p *GedisPool)Empty() {
-	var g *Gedis
-	for {
-		select {
-		case g <- p.pool:
-			g.Close()
-		default:
-			return
-		}
+	p.pool.empty()
+}
+
+// 停止后台检查并关闭所有空闲的连接
+func (p *GedisPool)Close() {
+	p.pool.close()
+}
+
+// 连接池PING检查的读写超时，避免在被NAT、负载均衡等设备静默丢弃的连接上一直等待
+const poolPingTimeout = 3 * time.Second
+
+// 连接没有设置超时或超时长于poolPingTimeout时，PING使用poolPingTimeout
+func pingGedis(g *Gedis) error {
+	timeout := g.conn.timeout
+	if timeout == 0 || timeout > poolPingTimeout {
+		g.conn.timeout = poolPingTimeout
+		defer func() {
+			g.conn.timeout = timeout
+			if timeout == 0 {
+				g.conn.Conn.SetDeadline(time.Time{})
+			}
+		}()
 	}
+	_, err := g.Ping()
+	return err
 }
 
-//type PoolConfig struct {
-//	size    int
-//
-//	maxIdle int
-//
-//	minIdle int
-//}
+// 真正关闭连接而不是还回连接池；直接关闭socket而不发送QUIT，
+// 被丢弃的多是已经不可用的连接，QUIT没有超时时会一直等待
+func closeGedis(g *Gedis) {
+	g.Pool = nil
+	g.conn.Close()
+}
@@ -0,0 +1,208 @@
+package gedis
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+// 主从复制相关的命令
+
+// 将当前节点设置为host:port的副本，成功后返回"OK"
+func (g *Gedis)ReplicaOf(host string, port int) (string, error) {
+	return g.Cmd("REPLICAOF", host, port).Str()
+}
+
+// 停止复制，将当前节点提升为master
+func (g *Gedis)ReplicaOfNoOne() (string, error) {
+	return g.Cmd("REPLICAOF", "NO", "ONE").Str()
+}
+
+const (
+	RoleMaster   = "master"
+	RoleReplica  = "slave"
+	RoleSentinel = "sentinel"
+)
+
+// ROLE命令的返回值，根据Kind的不同，Master/Replica/Sentinel中只有一个不为nil
+type Role struct {
+	Kind     string
+	Master   *MasterRole
+	Replica  *ReplicaRole
+	Sentinel *SentinelRole
+}
+
+type MasterRole struct {
+	ReplOffset int64
+	// 已连接的副本
+	Replicas   []ReplicaOffset
+}
+
+// master所看到的一个副本及其已确认的复制偏移量
+type ReplicaOffset struct {
+	Host   string
+	Port   int
+	Offset int64
+}
+
+type ReplicaRole struct {
+	MasterHost string
+	MasterPort int
+	// 与master的连接状态: connect、connecting、sync、connected
+	State      string
+	// 已接收的复制偏移量，尚未开始复制时为-1
+	Offset     int64
+}
+
+type SentinelRole struct {
+	// 该哨兵监控的master名称
+	MasterNames []string
+}
+
+func (g *Gedis)Role() (*Role, error) {
+	return parseRole(g.Cmd("ROLE"))
+}
+
+func parseRole(r *Reply) (*Role, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply || len(r.Children) == 0 {
+		return nil, errors.New("reply is not formatted as a ROLE reply")
+	}
+	kind, err := r.Children[0].Str()
+	if err != nil {
+		return nil, err
+	}
+	role := &Role{Kind: kind}
+	switch kind {
+	case RoleMaster:
+		if len(r.Children) != 3 {
+			return nil, errors.New("reply is not formatted as a master ROLE reply")
+		}
+		m := &MasterRole{}
+		if m.ReplOffset, err = r.Children[1].Int64(); err != nil {
+			return nil, err
+		}
+		for _, c := range r.Children[2].Children {
+			// [host, port, offset]，port和offset以字符串形式返回
+			list, err := c.List()
+			if err != nil {
+				return nil, err
+			}
+			if len(list) != 3 {
+				return nil, errors.New("reply is not formatted as a replica offset")
+			}
+			port, err := strconv.Atoi(list[1])
+			if err != nil {
+				return nil, err
+			}
+			offset, err := strconv.ParseInt(list[2], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			m.Replicas = append(m.Replicas, ReplicaOffset{Host: list[0], Port: port, Offset: offset})
+		}
+		role.Master = m
+	case RoleReplica:
+		if len(r.Children) != 5 {
+			return nil, errors.New("reply is not formatted as a replica ROLE reply")
+		}
+		s := &ReplicaRole{}
+		if s.MasterHost, err = r.Children[1].Str(); err != nil {
+			return nil, err
+		}
+		port, err := r.Children[2].Int64()
+		if err != nil {
+			return nil, err
+		}
+		s.MasterPort = int(port)
+		if s.State, err = r.Children[3].Str(); err != nil {
+			return nil, err
+		}
+		if s.Offset, err = r.Children[4].Int64(); err != nil {
+			return nil, err
+		}
+		role.Replica = s
+	case RoleSentinel:
+		if len(r.Children) != 2 {
+			return nil, errors.New("reply is not formatted as a sentinel ROLE reply")
+		}
+		names, err := r.Children[1].List()
+		if err != nil {
+			return nil, err
+		}
+		role.Sentinel = &SentinelRole{MasterNames: names}
+	default:
+		return nil, errors.New("unknown role: " + kind)
+	}
+	return role, nil
+}
+
+// 等待之前的写命令被至少numReplicas个副本确认，返回确认的副本数；timeout为0表示一直等待
+func (g *Gedis)Wait(numReplicas int, timeout time.Duration) (int64, error) {
+	return g.BlockingCmd(timeout, "WAIT", numReplicas, milliseconds(timeout)).Int64()
+}
+
+// 等待之前的写命令被本地及numReplicas个副本写入AOF(Redis 7.2+)，
+// 返回已写入AOF的本地节点数(0或1)及副本数；timeout为0表示一直等待
+func (g *Gedis)WaitAOF(numLocal, numReplicas int, timeout time.Duration) (int64, int64, error) {
+	r := g.BlockingCmd(timeout, "WAITAOF", numLocal, numReplicas, milliseconds(timeout))
+	if r.Type == QueuedReply {
+		return 0, 0, nil
+	}
+	list, err := intList(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(list) != 2 {
+		return 0, 0, errors.New("reply is not formatted as a WAITAOF reply")
+	}
+	return list[0], list[1], nil
+}
+
+// FAILOVER的可选参数
+type FailoverOptions struct {
+	// 指定切换到的副本，为空时由master选择
+	ToHost  string
+	ToPort  int
+	// 超时后即使副本没有追上也强制切换，需要同时指定ToHost和Timeout
+	Force   bool
+	Timeout time.Duration
+}
+
+// 在master上发起一次协调的主从切换，成功发起后返回"OK"
+func (g *Gedis)Failover(opt *FailoverOptions) (string, error) {
+	args := make([]interface{}, 0, 6)
+	if opt != nil {
+		if opt.ToHost != "" {
+			args = append(args, "TO", opt.ToHost, opt.ToPort)
+			if opt.Force {
+				args = append(args, "FORCE")
+			}
+		}
+		if opt.Timeout > 0 {
+			args = append(args, "TIMEOUT", milliseconds(opt.Timeout))
+		}
+	}
+	return g.Cmd("FAILOVER", args...).Str()
+}
+
+// 取消正在进行的FAILOVER
+func (g *Gedis)FailoverAbort() (string, error) {
+	return g.Cmd("FAILOVER", "ABORT").Str()
+}
+
+// 通过ROLE确认addr上的节点当前确实是master，连接由builder创建
+func isMaster(addr HostAndPort, builder PoolBuilder) bool {
+	g, err := builder(addr.GetHost(), addr.GetPort())
+	if err != nil {
+		return false
+	}
+	defer g.Close()
+	role, err := g.Role()
+	return err == nil && role.Kind == RoleMaster
+}
@@ -0,0 +1,121 @@
+package gedis
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseRole(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  *Role
+	}{
+		{"master", respArray(respBulk("master"), ":3129659\r\n", respArray(
+			respArray(respBulk("127.0.0.1"), respBulk("9001"), respBulk("3129242")),
+			respArray(respBulk("127.0.0.1"), respBulk("9002"), respBulk("3129543")),
+		)), &Role{Kind: RoleMaster, Master: &MasterRole{ReplOffset: 3129659, Replicas: []ReplicaOffset{
+			{"127.0.0.1", 9001, 3129242},
+			{"127.0.0.1", 9002, 3129543},
+		}}}},
+		{"master without replicas", respArray(respBulk("master"), ":0\r\n", respArray()),
+			&Role{Kind: RoleMaster, Master: &MasterRole{}}},
+		{"replica", respArray(respBulk("slave"), respBulk("127.0.0.1"), ":9000\r\n", respBulk("connected"), ":3167038\r\n"),
+			&Role{Kind: RoleReplica, Replica: &ReplicaRole{MasterHost: "127.0.0.1", MasterPort: 9000, State: "connected", Offset: 3167038}}},
+		{"replica not synced", respArray(respBulk("slave"), respBulk("10.0.0.1"), ":6379\r\n", respBulk("connect"), ":-1\r\n"),
+			&Role{Kind: RoleReplica, Replica: &ReplicaRole{MasterHost: "10.0.0.1", MasterPort: 6379, State: "connect", Offset: -1}}},
+		{"sentinel", respArray(respBulk("sentinel"), respArray(respBulk("resque-master"), respBulk("html-fragments-master"))),
+			&Role{Kind: RoleSentinel, Sentinel: &SentinelRole{MasterNames: []string{"resque-master", "html-fragments-master"}}}},
+	}
+	for _, tt := range tests {
+		role, err := parseRole(mustReply(t, tt.reply))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(role, tt.want) {
+			t.Errorf("%s: %+v, want %+v", tt.name, role, tt.want)
+		}
+	}
+}
+
+func TestParseRoleInvalid(t *testing.T) {
+	for _, bad := range []string{
+		"-ERR unknown command\r\n",
+		respArray(),
+		respArray(respBulk("primary")),
+		respArray(respBulk("master"), ":1\r\n"),
+		respArray(respBulk("master"), ":1\r\n", respArray(respArray(respBulk("h"), respBulk("port"), respBulk("1")))),
+		respArray(respBulk("slave"), respBulk("h"), ":1\r\n"),
+		respArray(respBulk("sentinel")),
+	} {
+		if _, err := parseRole(mustReply(t, bad)); err == nil {
+			t.Errorf("parseRole(%q) should fail", bad)
+		}
+	}
+}
+
+func TestWaitTimeoutArgs(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    string
+	}{
+		{0, "0"},
+		{1500 * time.Millisecond, "1500"},
+		// 不足1毫秒时不能变成0(一直等待)
+		{300 * time.Microsecond, "1"},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis(":1\r\n", respArray(":1\r\n", ":1\r\n"), "+OK\r\n")
+		if _, err := g.Wait(1, tt.timeout); err != nil {
+			t.Fatalf("Wait: %v", err)
+		}
+		if _, _, err := g.WaitAOF(1, 1, tt.timeout); err != nil {
+			t.Fatalf("WaitAOF: %v", err)
+		}
+		want := respCommand("WAIT", "1", tt.want) + respCommand("WAITAOF", "1", "1", tt.want)
+		if tt.timeout > 0 {
+			if _, err := g.Failover(&FailoverOptions{Timeout: tt.timeout}); err != nil {
+				t.Fatalf("Failover: %v", err)
+			}
+			want += respCommand("FAILOVER", "TIMEOUT", tt.want)
+		}
+		if fc.written.String() != want {
+			t.Errorf("timeout %v: written %q, want %q", tt.timeout, fc.written.String(), want)
+		}
+	}
+}
+
+func TestIsMasterUsesBuilder(t *testing.T) {
+	master := respArray(respBulk("master"), ":0\r\n", respArray())
+	replica := respArray(respBulk("slave"), respBulk("127.0.0.1"), ":6379\r\n", respBulk("connected"), ":0\r\n")
+	tests := []struct {
+		name    string
+		builder PoolBuilder
+		want    bool
+	}{
+		{"master", func(host string, port int) (*Gedis, error) { g, _ := newFakeGedis(master); return g, nil }, true},
+		{"replica", func(host string, port int) (*Gedis, error) { g, _ := newFakeGedis(replica); return g, nil }, false},
+		{"noauth", func(host string, port int) (*Gedis, error) {
+			g, _ := newFakeGedis("-NOAUTH Authentication required.\r\n")
+			return g, nil
+		}, false},
+		{"dial error", func(host string, port int) (*Gedis, error) { return nil, errors.New("refused") }, false},
+	}
+	addr := HostAndPort{host: "10.0.0.1", port: 6379}
+	for _, tt := range tests {
+		var dialed HostAndPort
+		builder := func(host string, port int) (*Gedis, error) {
+			dialed = HostAndPort{host: host, port: port}
+			return tt.builder(host, port)
+		}
+		if got := isMaster(addr, builder); got != tt.want {
+			t.Errorf("%s: isMaster = %v, want %v", tt.name, got, tt.want)
+		}
+		if dialed != addr {
+			t.Errorf("%s: builder dialed %v, want %v", tt.name, dialed, addr)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package gedis
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SENTINEL相关的命令，需要连接到哨兵节点执行
+
+// 哨兵所看到的一个实例(master、副本或其它哨兵)的公共字段，未列出的字段保存在Fields中
+type SentinelInstance struct {
+	Name                string
+	IP                  string
+	Port                int
+	RunID               string
+	// 如master、slave、s_down、o_down、disconnected等
+	Flags               []string
+	LinkPendingCommands int64
+	LinkRefcount        int64
+	// 距上次发送PING/收到PING回复/收到有效PING回复的时长
+	LastPingSent        time.Duration
+	LastPingReply       time.Duration
+	LastOkPingReply     time.Duration
+	DownAfter           time.Duration
+
+	Fields              map[string]string
+}
+
+// 是否带有flag，如 HasFlag("s_down")
+func (i *SentinelInstance)HasFlag(flag string) bool {
+	for _, f := range i.Flags {
+		if f == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// 实例是否被主观或客观地判定为下线
+func (i *SentinelInstance)Down() bool {
+	return i.HasFlag("s_down") || i.HasFlag("o_down")
+}
+
+// SENTINEL MASTERS/MASTER中的一个master
+type SentinelMaster struct {
+	SentinelInstance
+	// 距上次从该实例获取INFO的时长
+	InfoRefresh       time.Duration
+	RoleReported      string
+	ConfigEpoch       int64
+	NumSlaves         int64
+	NumOtherSentinels int64
+	Quorum            int64
+	FailoverTimeout   time.Duration
+	ParallelSyncs     int64
+}
+
+// SENTINEL REPLICAS中的一个副本
+type SentinelReplica struct {
+	SentinelInstance
+	InfoRefresh        time.Duration
+	RoleReported       string
+	// 副本与master之间的连接状态，"ok"或"err"
+	MasterLinkStatus   string
+	// 副本与master断开的时长，连接正常时为0
+	MasterLinkDownTime time.Duration
+	MasterHost         string
+	MasterPort         int
+	SlavePriority      int64
+	SlaveReplOffset    int64
+}
+
+// SENTINEL SENTINELS中的一个其它哨兵
+type SentinelPeer struct {
+	SentinelInstance
+	// 距上次收到该哨兵hello消息的时长
+	LastHelloMessage time.Duration
+	VotedLeader      string
+	VotedLeaderEpoch int64
+}
+
+func parseSentinelInstance(r *Reply) (SentinelInstance, map[string]*Reply, error) {
+	m, err := kvReplies(r)
+	if err != nil {
+		return SentinelInstance{}, nil, err
+	}
+	ms := func(k string) time.Duration {
+		return time.Duration(kvInt(m, k)) * time.Millisecond
+	}
+	i := SentinelInstance{
+		Name: kvStr(m, "name"),
+		IP: kvStr(m, "ip"),
+		Port: int(kvInt(m, "port")),
+		RunID: kvStr(m, "runid"),
+		LinkPendingCommands: kvInt(m, "link-pending-commands"),
+		LinkRefcount: kvInt(m, "link-refcount"),
+		LastPingSent: ms("last-ping-sent"),
+		LastPingReply: ms("last-ping-reply"),
+		LastOkPingReply: ms("last-ok-ping-reply"),
+		DownAfter: ms("down-after-milliseconds"),
+		Fields: make(map[string]string, len(m)),
+	}
+	if flags := kvStr(m, "flags"); flags != "" {
+		i.Flags = strings.Split(flags, ",")
+	}
+	for k := range m {
+		i.Fields[k] = kvStr(m, k)
+	}
+	return i, m, nil
+}
+
+func parseSentinelMaster(r *Reply) (*SentinelMaster, error) {
+	i, m, err := parseSentinelInstance(r)
+	if err != nil {
+		return nil, err
+	}
+	return &SentinelMaster{
+		SentinelInstance: i,
+		InfoRefresh: time.Duration(kvInt(m, "info-refresh")) * time.Millisecond,
+		RoleReported: kvStr(m, "role-reported"),
+		ConfigEpoch: kvInt(m, "config-epoch"),
+		NumSlaves: kvInt(m, "num-slaves"),
+		NumOtherSentinels: kvInt(m, "num-other-sentinels"),
+		Quorum: kvInt(m, "quorum"),
+		FailoverTimeout: time.Duration(kvInt(m, "failover-timeout")) * time.Millisecond,
+		ParallelSyncs: kvInt(m, "parallel-syncs"),
+	}, nil
+}
+
+// 返回该哨兵监控的所有master
+func (g *Gedis)SentinelMasters() ([]SentinelMaster, error) {
+	r := g.Cmd("SENTINEL", "MASTERS")
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	masters := make([]SentinelMaster, len(r.Children))
+	for i, c := range r.Children {
+		m, err := parseSentinelMaster(c)
+		if err != nil {
+			return nil, err
+		}
+		masters[i] = *m
+	}
+	return masters, nil
+}
+
+func (g *Gedis)SentinelMaster(name string) (*SentinelMaster, error) {
+	return parseSentinelMaster(g.Cmd("SENTINEL", "MASTER", name))
+}
+
+// 返回master的所有副本
+func (g *Gedis)SentinelReplicas(name string) ([]SentinelReplica, error) {
+	r := g.Cmd("SENTINEL", "REPLICAS", name)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	replicas := make([]SentinelReplica, len(r.Children))
+	for n, c := range r.Children {
+		replica, err := parseSentinelReplica(c)
+		if err != nil {
+			return nil, err
+		}
+		replicas[n] = *replica
+	}
+	return replicas, nil
+}
+
+func parseSentinelReplica(r *Reply) (*SentinelReplica, error) {
+	i, m, err := parseSentinelInstance(r)
+	if err != nil {
+		return nil, err
+	}
+	return &SentinelReplica{
+		SentinelInstance: i,
+		InfoRefresh: time.Duration(kvInt(m, "info-refresh")) * time.Millisecond,
+		RoleReported: kvStr(m, "role-reported"),
+		MasterLinkStatus: kvStr(m, "master-link-status"),
+		MasterLinkDownTime: time.Duration(kvInt(m, "master-link-down-time")) * time.Millisecond,
+		MasterHost: kvStr(m, "master-host"),
+		MasterPort: int(kvInt(m, "master-port")),
+		SlavePriority: kvInt(m, "slave-priority"),
+		SlaveReplOffset: kvInt(m, "slave-repl-offset"),
+	}, nil
+}
+
+// 返回同样监控该master的其它哨兵
+func (g *Gedis)SentinelSentinels(name string) ([]SentinelPeer, error) {
+	r := g.Cmd("SENTINEL", "SENTINELS", name)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	peers := make([]SentinelPeer, len(r.Children))
+	for n, c := range r.Children {
+		peer, err := parseSentinelPeer(c)
+		if err != nil {
+			return nil, err
+		}
+		peers[n] = *peer
+	}
+	return peers, nil
+}
+
+func parseSentinelPeer(r *Reply) (*SentinelPeer, error) {
+	i, m, err := parseSentinelInstance(r)
+	if err != nil {
+		return nil, err
+	}
+	return &SentinelPeer{
+		SentinelInstance: i,
+		LastHelloMessage: time.Duration(kvInt(m, "last-hello-message")) * time.Millisecond,
+		VotedLeader: kvStr(m, "voted-leader"),
+		VotedLeaderEpoch: kvInt(m, "voted-leader-epoch"),
+	}, nil
+}
+
+// 返回master的地址，哨兵没有监控该master时返回ErrNil
+func (g *Gedis)SentinelGetMasterAddrByName(name string) (HostAndPort, error) {
+	r := g.Cmd("SENTINEL", SENTINEL_GET_MASTER_ADDR_BY_NAME, name)
+	if r.Type == NilReply {
+		return HostAndPort{}, ErrNil
+	}
+	if r.Type == QueuedReply {
+		return HostAndPort{}, nil
+	}
+	list, err := r.List()
+	if err != nil {
+		return HostAndPort{}, err
+	}
+	if len(list) != 2 {
+		return HostAndPort{}, errors.New("reply is not formatted as a master address")
+	}
+	port, err := strconv.Atoi(list[1])
+	if err != nil {
+		return HostAndPort{}, err
+	}
+	return HostAndPort{host: list[0], port: port}, nil
+}
+
+// 检查当前的哨兵数量是否足以达到failover所需的quorum及多数授权，可以时返回描述信息，否则返回错误
+func (g *Gedis)SentinelCKQuorum(name string) (string, error) {
+	return g.Cmd("SENTINEL", "CKQUORUM", name).Str()
+}
+
+// 不经其它哨兵同意，强制进行一次failover
+func (g *Gedis)SentinelFailover(name string) (string, error) {
+	return g.Cmd("SENTINEL", "FAILOVER", name).Str()
+}
+
+// 重置名称匹配pattern的master的状态，返回重置的master数
+func (g *Gedis)SentinelReset(pattern string) (int64, error) {
+	return g.Cmd("SENTINEL", "RESET", pattern).Int64()
+}
+
+// 开始监控一个新的master，成功后返回"OK"
+func (g *Gedis)SentinelMonitor(name, host string, port int, quorum int) (string, error) {
+	return g.Cmd("SENTINEL", "MONITOR", name, host, port, quorum).Str()
+}
+
+// 停止监控master
+func (g *Gedis)SentinelRemove(name string) (string, error) {
+	return g.Cmd("SENTINEL", "REMOVE", name).Str()
+}
+
+// 修改master的监控配置，如 down-after-milliseconds、failover-timeout、parallel-syncs、quorum
+func (g *Gedis)SentinelSet(name string, params map[string]interface{}) (string, error) {
+	return g.Cmd("SENTINEL", "SET", name, flattenPairs(params)).Str()
+}
+
+// 哨兵缓存的一个实例的INFO，Info为nil表示还没有获取到
+type SentinelInfoCache struct {
+	// 缓存的时长
+	Age  time.Duration
+	Info *Info
+}
+
+// 返回哨兵缓存的master及其副本的INFO，key为master名称，每个slice中第一个为master；
+// 不指定names时返回所有master
+func (g *Gedis)SentinelInfoCache(names... string) (map[string][]SentinelInfoCache, error) {
+	return parseSentinelInfoCache(g.Cmd("SENTINEL", "INFO-CACHE", names))
+}
+
+func parseSentinelInfoCache(r *Reply) (map[string][]SentinelInfoCache, error) {
+	m, err := kvReplies(r)
+	if err != nil {
+		return nil, err
+	}
+	caches := make(map[string][]SentinelInfoCache, len(m))
+	for name, entries := range m {
+		list := make([]SentinelInfoCache, len(entries.Children))
+		for i, c := range entries.Children {
+			if len(c.Children) != 2 {
+				return nil, errors.New("reply is not formatted as an INFO-CACHE entry")
+			}
+			age, err := c.Children[0].Int64()
+			if err != nil {
+				return nil, err
+			}
+			list[i].Age = time.Duration(age) * time.Millisecond
+			if text, err := c.Children[1].Str(); err == nil {
+				list[i].Info = ParseInfo(text)
+			}
+		}
+		caches[name] = list
+	}
+	return caches, nil
+}
@@ -0,0 +1,151 @@
+package gedis
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseSentinelInstance(t *testing.T) {
+	r := mustReply(t, respCommand(
+		"name", "mymaster", "ip", "10.0.0.1", "port", "6379", "runid", "abc",
+		"flags", "master,s_down", "link-pending-commands", "2", "link-refcount", "1",
+		"last-ping-sent", "0", "last-ok-ping-reply", "120", "last-ping-reply", "130",
+		"down-after-milliseconds", "5000", "pending-commands", "7",
+	))
+	i, m, err := parseSentinelInstance(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.Name != "mymaster" || i.IP != "10.0.0.1" || i.Port != 6379 || i.RunID != "abc" ||
+		i.LinkPendingCommands != 2 || i.LinkRefcount != 1 {
+		t.Errorf("instance = %+v", i)
+	}
+	if i.LastOkPingReply != 120*time.Millisecond || i.LastPingReply != 130*time.Millisecond || i.DownAfter != 5*time.Second {
+		t.Errorf("durations = %v %v %v", i.LastOkPingReply, i.LastPingReply, i.DownAfter)
+	}
+	if !reflect.DeepEqual(i.Flags, []string{"master", "s_down"}) || !i.HasFlag("s_down") || !i.Down() {
+		t.Errorf("Flags = %v", i.Flags)
+	}
+	if i.Fields["pending-commands"] != "7" || len(m) != len(i.Fields) {
+		t.Errorf("Fields = %v", i.Fields)
+	}
+
+	i, _, err = parseSentinelInstance(mustReply(t, respCommand("name", "m", "flags", "")))
+	if err != nil || i.Flags != nil || i.Down() {
+		t.Errorf("instance without flags = %+v, %v", i, err)
+	}
+	if _, _, err := parseSentinelInstance(mustReply(t, respCommand("name"))); err == nil {
+		t.Error("odd number of fields was accepted")
+	}
+}
+
+func TestParseSentinelMaster(t *testing.T) {
+	r := mustReply(t, respCommand(
+		"name", "mymaster", "flags", "master", "info-refresh", "2500", "role-reported", "master",
+		"config-epoch", "3", "num-slaves", "2", "num-other-sentinels", "2", "quorum", "2",
+		"failover-timeout", "180000", "parallel-syncs", "1",
+	))
+	m, err := parseSentinelMaster(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SentinelMaster{
+		InfoRefresh: 2500 * time.Millisecond, RoleReported: "master", ConfigEpoch: 3, NumSlaves: 2,
+		NumOtherSentinels: 2, Quorum: 2, FailoverTimeout: 3 * time.Minute, ParallelSyncs: 1,
+	}
+	want.SentinelInstance = m.SentinelInstance
+	if !reflect.DeepEqual(*m, want) || m.Name != "mymaster" {
+		t.Errorf("parseSentinelMaster = %+v, want %+v", *m, want)
+	}
+}
+
+func TestParseSentinelReplicaAndPeer(t *testing.T) {
+	replica, err := parseSentinelReplica(mustReply(t, respCommand(
+		"name", "10.0.0.2:6379", "ip", "10.0.0.2", "port", "6379", "flags", "slave",
+		"master-link-status", "err", "master-link-down-time", "3000",
+		"master-host", "10.0.0.1", "master-port", "6380", "slave-priority", "100", "slave-repl-offset", "42",
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replica.Port != 6379 || replica.MasterLinkStatus != "err" || replica.MasterLinkDownTime != 3*time.Second ||
+		replica.MasterHost != "10.0.0.1" || replica.MasterPort != 6380 || replica.SlavePriority != 100 || replica.SlaveReplOffset != 42 {
+		t.Errorf("replica = %+v", replica)
+	}
+
+	peer, err := parseSentinelPeer(mustReply(t, respCommand(
+		"name", "s2", "ip", "10.0.0.9", "port", "26379", "flags", "sentinel",
+		"last-hello-message", "400", "voted-leader", "?", "voted-leader-epoch", "0",
+	)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peer.Port != 26379 || peer.LastHelloMessage != 400*time.Millisecond || peer.VotedLeader != "?" || !peer.HasFlag("sentinel") {
+		t.Errorf("peer = %+v", peer)
+	}
+
+	g, _ := newFakeGedis(respArray(respCommand("name", "a"), respCommand("name", "b")))
+	replicas, err := g.SentinelReplicas("mymaster")
+	if err != nil || len(replicas) != 2 || replicas[1].Name != "b" {
+		t.Errorf("SentinelReplicas = %+v, %v", replicas, err)
+	}
+}
+
+func TestSentinelGetMasterAddrByName(t *testing.T) {
+	g, fc := newFakeGedis(respArray(respBulk("10.0.0.1"), respBulk("6380")))
+	addr, err := g.SentinelGetMasterAddrByName("mymaster")
+	if err != nil || addr != (HostAndPort{host: "10.0.0.1", port: 6380}) {
+		t.Errorf("SentinelGetMasterAddrByName = %v, %v", addr, err)
+	}
+	if w := respCommand("SENTINEL", "get-master-addr-by-name", "mymaster"); fc.written.String() != w {
+		t.Errorf("written = %q, want %q", fc.written.String(), w)
+	}
+
+	tests := []struct {
+		name  string
+		reply string
+	}{
+		{"nil", "*-1\r\n"},
+		{"bad port", respArray(respBulk("10.0.0.1"), respBulk("x"))},
+		{"short", respArray(respBulk("10.0.0.1"))},
+		{"error", "-ERR unknown master\r\n"},
+	}
+	for _, tt := range tests {
+		g, _ := newFakeGedis(tt.reply)
+		addr, err := g.SentinelGetMasterAddrByName("mymaster")
+		if err == nil || addr != (HostAndPort{}) {
+			t.Errorf("%s: SentinelGetMasterAddrByName = %v, %v, want an error", tt.name, addr, err)
+		}
+		if tt.name == "nil" && err != ErrNil {
+			t.Errorf("nil reply err = %v, want ErrNil", err)
+		}
+	}
+}
+
+func TestParseSentinelInfoCache(t *testing.T) {
+	r := mustReply(t, respArray(
+		respBulk("mymaster"), respArray(
+			respArray(":1200\r\n", respBulk("# Replication\r\nrole:master\r\nconnected_slaves:1\r\n")),
+			respArray(":300\r\n", "$-1\r\n"),
+		),
+	))
+	caches, err := parseSentinelInfoCache(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := caches["mymaster"]
+	if len(list) != 2 {
+		t.Fatalf("caches = %+v", caches)
+	}
+	if list[0].Age != 1200*time.Millisecond || list[0].Info == nil || list[0].Info.Get("replication", "role") != "master" {
+		t.Errorf("master cache = %+v", list[0])
+	}
+	if list[1].Age != 300*time.Millisecond || list[1].Info != nil {
+		t.Errorf("replica cache = %+v", list[1])
+	}
+
+	if _, err := parseSentinelInfoCache(mustReply(t, respArray(respBulk("m"), respArray(respArray(":1\r\n"))))); err == nil {
+		t.Error("malformed entry was accepted")
+	}
+}
@@ -0,0 +1,363 @@
+package gedis
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// 服务端管理相关的命令
+
+// 解析后的INFO，按section分组，section名及field名与INFO输出一致(section名为小写)
+type Info struct {
+	Sections map[string]map[string]string
+}
+
+// 解析INFO命令返回的文本
+func ParseInfo(text string) *Info {
+	info := &Info{Sections: make(map[string]map[string]string)}
+	section := ""
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			section = strings.ToLower(strings.TrimSpace(line[1:]))
+			continue
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			continue
+		}
+		if info.Sections[section] == nil {
+			info.Sections[section] = make(map[string]string)
+		}
+		info.Sections[section][line[:i]] = line[i + 1:]
+	}
+	return info
+}
+
+// 返回sections对应的INFO，不指定时返回默认的section
+func (g *Gedis)Info(sections... string) (*Info, error) {
+	text, err := g.Cmd("INFO", sections).Str()
+	if err != nil {
+		return nil, err
+	}
+	return ParseInfo(text), nil
+}
+
+// 返回field的原始值，不存在时返回""
+func (i *Info)Get(section, field string) string {
+	return i.Sections[section][field]
+}
+
+// 返回field的整数值，不存在或不是整数时返回0
+func (i *Info)Int(section, field string) int64 {
+	n, _ := strconv.ParseInt(i.Get(section, field), 10, 64)
+	return n
+}
+
+func (i *Info)Float(section, field string) float64 {
+	f, _ := strconv.ParseFloat(i.Get(section, field), 64)
+	return f
+}
+
+type InfoReplication struct {
+	Role             string
+	ConnectedSlaves  int64
+	MasterHost       string
+	MasterPort       int
+	// "up"或"down"，只在副本上有值
+	MasterLinkStatus string
+	MasterReplOffset int64
+	Slaves           []InfoSlave
+}
+
+// master上INFO replication中的slave0、slave1...
+type InfoSlave struct {
+	IP     string
+	Port   int
+	State  string
+	Offset int64
+	Lag    int64
+}
+
+func (i *Info)Replication() InfoReplication {
+	const s = "replication"
+	r := InfoReplication{
+		Role: i.Get(s, "role"),
+		ConnectedSlaves: i.Int(s, "connected_slaves"),
+		MasterHost: i.Get(s, "master_host"),
+		MasterPort: int(i.Int(s, "master_port")),
+		MasterLinkStatus: i.Get(s, "master_link_status"),
+		MasterReplOffset: i.Int(s, "master_repl_offset"),
+	}
+	for n := int64(0); n < r.ConnectedSlaves; n++ {
+		v := i.Get(s, "slave" + strconv.FormatInt(n, 10))
+		if v == "" {
+			continue
+		}
+		// ip=127.0.0.1,port=6380,state=online,offset=123,lag=0
+		kv := parseCommaKV(v)
+		port, _ := strconv.Atoi(kv["port"])
+		offset, _ := strconv.ParseInt(kv["offset"], 10, 64)
+		lag, _ := strconv.ParseInt(kv["lag"], 10, 64)
+		r.Slaves = append(r.Slaves, InfoSlave{
+			IP: kv["ip"],
+			Port: port,
+			State: kv["state"],
+			Offset: offset,
+			Lag: lag,
+		})
+	}
+	return r
+}
+
+type InfoMemory struct {
+	UsedMemory            int64
+	UsedMemoryRSS         int64
+	UsedMemoryPeak        int64
+	MaxMemory             int64
+	MaxMemoryPolicy       string
+	MemFragmentationRatio float64
+}
+
+func (i *Info)Memory() InfoMemory {
+	const s = "memory"
+	return InfoMemory{
+		UsedMemory: i.Int(s, "used_memory"),
+		UsedMemoryRSS: i.Int(s, "used_memory_rss"),
+		UsedMemoryPeak: i.Int(s, "used_memory_peak"),
+		MaxMemory: i.Int(s, "maxmemory"),
+		MaxMemoryPolicy: i.Get(s, "maxmemory_policy"),
+		MemFragmentationRatio: i.Float(s, "mem_fragmentation_ratio"),
+	}
+}
+
+type InfoClients struct {
+	ConnectedClients int64
+	BlockedClients   int64
+	MaxClients       int64
+}
+
+func (i *Info)Clients() InfoClients {
+	const s = "clients"
+	return InfoClients{
+		ConnectedClients: i.Int(s, "connected_clients"),
+		BlockedClients: i.Int(s, "blocked_clients"),
+		MaxClients: i.Int(s, "maxclients"),
+	}
+}
+
+type InfoPersistence struct {
+	Loading                 bool
+	RDBChangesSinceLastSave int64
+	RDBBgSaveInProgress     bool
+	RDBLastSaveTime         time.Time
+	RDBLastBgSaveStatus     string
+	AOFEnabled              bool
+	AOFRewriteInProgress    bool
+	AOFLastBgRewriteStatus  string
+}
+
+func (i *Info)Persistence() InfoPersistence {
+	const s = "persistence"
+	return InfoPersistence{
+		Loading: i.Get(s, "loading") == "1",
+		RDBChangesSinceLastSave: i.Int(s, "rdb_changes_since_last_save"),
+		RDBBgSaveInProgress: i.Get(s, "rdb_bgsave_in_progress") == "1",
+		RDBLastSaveTime: time.Unix(i.Int(s, "rdb_last_save_time"), 0),
+		RDBLastBgSaveStatus: i.Get(s, "rdb_last_bgsave_status"),
+		AOFEnabled: i.Get(s, "aof_enabled") == "1",
+		AOFRewriteInProgress: i.Get(s, "aof_rewrite_in_progress") == "1",
+		AOFLastBgRewriteStatus: i.Get(s, "aof_last_bgrewrite_status"),
+	}
+}
+
+// INFO keyspace中一个db的统计
+type KeyspaceInfo struct {
+	Keys    int64
+	Expires int64
+	AvgTTL  time.Duration
+}
+
+// 返回每个db的统计，key为db的下标
+func (i *Info)Keyspace() map[int]KeyspaceInfo {
+	keyspace := make(map[int]KeyspaceInfo)
+	for field, v := range i.Sections["keyspace"] {
+		// db0:keys=1,expires=0,avg_ttl=0
+		if !strings.HasPrefix(field, "db") {
+			continue
+		}
+		db, err := strconv.Atoi(field[2:])
+		if err != nil {
+			continue
+		}
+		kv := parseCommaKV(v)
+		keys, _ := strconv.ParseInt(kv["keys"], 10, 64)
+		expires, _ := strconv.ParseInt(kv["expires"], 10, 64)
+		avgTTL, _ := strconv.ParseInt(kv["avg_ttl"], 10, 64)
+		keyspace[db] = KeyspaceInfo{
+			Keys: keys,
+			Expires: expires,
+			AvgTTL: time.Duration(avgTTL) * time.Millisecond,
+		}
+	}
+	return keyspace
+}
+
+// 解析 k1=v1,k2=v2 形式的值
+func parseCommaKV(s string) map[string]string {
+	kv := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if i := strings.IndexByte(pair, '='); i > 0 {
+			kv[pair[:i]] = pair[i + 1:]
+		}
+	}
+	return kv
+}
+
+// 返回匹配pattern的配置项
+func (g *Gedis)ConfigGet(pattern string) (map[string]string, error) {
+	return g.Cmd("CONFIG", "GET", pattern).Hash()
+}
+
+// 设置一个或多个配置项，成功后返回"OK"
+func (g *Gedis)ConfigSet(params map[string]interface{}) (string, error) {
+	return g.Cmd("CONFIG", "SET", flattenPairs(params)).Str()
+}
+
+func (g *Gedis)ConfigResetStat() (string, error) {
+	return g.Cmd("CONFIG", "RESETSTAT").Str()
+}
+
+// 将当前配置写回配置文件
+func (g *Gedis)ConfigRewrite() (string, error) {
+	return g.Cmd("CONFIG", "REWRITE").Str()
+}
+
+func (g *Gedis)DBSize() (int64, error) {
+	return g.Cmd("DBSIZE").Int64()
+}
+
+// FLUSHDB/FLUSHALL的执行方式，为空时使用服务端的lazyfree-lazy-user-flush配置
+type FlushMode string
+
+const (
+	FlushDefault FlushMode = ""
+	FlushAsync   FlushMode = "ASYNC"
+	FlushSync    FlushMode = "SYNC"
+)
+
+func (g *Gedis)FlushDB(mode FlushMode) (string, error) {
+	if mode == FlushDefault {
+		return g.Cmd("FLUSHDB").Str()
+	}
+	return g.Cmd("FLUSHDB", string(mode)).Str()
+}
+
+func (g *Gedis)FlushAll(mode FlushMode) (string, error) {
+	if mode == FlushDefault {
+		return g.Cmd("FLUSHALL").Str()
+	}
+	return g.Cmd("FLUSHALL", string(mode)).Str()
+}
+
+// 后台保存RDB，schedule为true时若正在执行AOF重写则等其完成后再执行
+func (g *Gedis)BgSave(schedule bool) (string, error) {
+	if schedule {
+		return g.Cmd("BGSAVE", "SCHEDULE").Str()
+	}
+	return g.Cmd("BGSAVE").Str()
+}
+
+func (g *Gedis)BgRewriteAOF() (string, error) {
+	return g.Cmd("BGREWRITEAOF").Str()
+}
+
+// 返回最近一次成功保存RDB的时间
+func (g *Gedis)LastSave() (time.Time, error) {
+	n, err := g.Cmd("LASTSAVE").Int64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(n, 0), nil
+}
+
+// 返回服务端的当前时间
+func (g *Gedis)Time() (time.Time, error) {
+	r := g.Cmd("TIME")
+	if r.Type == QueuedReply {
+		return time.Time{}, nil
+	}
+	list, err := r.List()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(list) != 2 {
+		return time.Time{}, errors.New("reply is not formatted as a TIME reply")
+	}
+	sec, err := strconv.ParseInt(list[0], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	usec, err := strconv.ParseInt(list[1], 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, usec * int64(time.Microsecond)), nil
+}
+
+// SHUTDOWN的可选参数，NoSave与Save互斥
+type ShutdownOptions struct {
+	NoSave bool
+	Save   bool
+	// 不等待副本追上复制进度
+	Now    bool
+	// 忽略保存RDB/AOF时的错误
+	Force  bool
+	// 取消正在进行的shutdown，此时其它参数无效
+	Abort  bool
+}
+
+// 执行SHUTDOWN，成功时服务端直接关闭连接，此时返回nil
+func (g *Gedis)ShutdownWithOptions(opt *ShutdownOptions) error {
+	args := make([]interface{}, 0, 3)
+	if opt != nil {
+		if opt.Abort {
+			_, err := g.Cmd("SHUTDOWN", "ABORT").Str()
+			return err
+		}
+		if opt.NoSave {
+			args = append(args, "NOSAVE")
+		} else if opt.Save {
+			args = append(args, "SAVE")
+		}
+		if opt.Now {
+			args = append(args, "NOW")
+		}
+		if opt.Force {
+			args = append(args, "FORCE")
+		}
+	}
+	r := g.Cmd("SHUTDOWN", args...)
+	if r.Type == ErrorReply && !isConnClosed(r.Err) {
+		return r.Err
+	}
+	return nil
+}
+
+// 错误是否表示连接已被对端关闭(EOF、连接被重置等)，读超时不算
+func isConnClosed(err error) bool {
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE)
+}
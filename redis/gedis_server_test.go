@@ -0,0 +1,120 @@
+package gedis
+
+import (
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const testInfo = "# Server\r\n" +
+	"redis_version:7.2.4\r\n" +
+	"run_id:abc\r\n" +
+	"\r\n" +
+	"# Clients\r\n" +
+	"connected_clients:3\r\n" +
+	"blocked_clients:1\r\n" +
+	"maxclients:10000\r\n" +
+	"\r\n" +
+	"# Memory\r\n" +
+	"used_memory:1024\r\n" +
+	"maxmemory_policy:allkeys-lru\r\n" +
+	"mem_fragmentation_ratio:1.25\r\n" +
+	"\r\n" +
+	"# Persistence\r\n" +
+	"loading:0\r\n" +
+	"rdb_bgsave_in_progress:1\r\n" +
+	"rdb_last_save_time:1700000000\r\n" +
+	"aof_enabled:1\r\n" +
+	"\r\n" +
+	"# Replication\r\n" +
+	"role:master\r\n" +
+	"connected_slaves:2\r\n" +
+	"slave0:ip=10.0.0.2,port=6380,state=online,offset=123,lag=0\r\n" +
+	"slave1:ip=10.0.0.3,port=6381,state=wait_bgsave,offset=0,lag=2\r\n" +
+	"master_repl_offset:123\r\n" +
+	"\r\n" +
+	"# Keyspace\r\n" +
+	"db0:keys=10,expires=2,avg_ttl=3000\r\n" +
+	"db12:keys=1,expires=0,avg_ttl=0\r\n"
+
+func TestParseInfo(t *testing.T) {
+	info := ParseInfo(testInfo)
+	tests := []struct {
+		section, field, want string
+	}{
+		{"server", "redis_version", "7.2.4"},
+		{"server", "run_id", "abc"},
+		{"memory", "maxmemory_policy", "allkeys-lru"},
+		{"replication", "slave0", "ip=10.0.0.2,port=6380,state=online,offset=123,lag=0"},
+		{"server", "missing", ""},
+		{"missing", "redis_version", ""},
+	}
+	for _, tt := range tests {
+		if got := info.Get(tt.section, tt.field); got != tt.want {
+			t.Errorf("Get(%q, %q) = %q, want %q", tt.section, tt.field, got, tt.want)
+		}
+	}
+	if n := info.Int("clients", "connected_clients"); n != 3 {
+		t.Errorf("connected_clients = %d", n)
+	}
+	if n := info.Int("server", "redis_version"); n != 0 {
+		t.Errorf("Int of non-integer = %d", n)
+	}
+	if f := info.Float("memory", "mem_fragmentation_ratio"); f != 1.25 {
+		t.Errorf("mem_fragmentation_ratio = %v", f)
+	}
+
+	c := info.Clients()
+	if c != (InfoClients{ConnectedClients: 3, BlockedClients: 1, MaxClients: 10000}) {
+		t.Errorf("Clients = %+v", c)
+	}
+	p := info.Persistence()
+	if p.Loading || !p.RDBBgSaveInProgress || !p.AOFEnabled || !p.RDBLastSaveTime.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("Persistence = %+v", p)
+	}
+	r := info.Replication()
+	if r.Role != "master" || r.ConnectedSlaves != 2 || r.MasterReplOffset != 123 || len(r.Slaves) != 2 {
+		t.Fatalf("Replication = %+v", r)
+	}
+	if r.Slaves[1] != (InfoSlave{IP: "10.0.0.3", Port: 6381, State: "wait_bgsave", Offset: 0, Lag: 2}) {
+		t.Errorf("Slaves[1] = %+v", r.Slaves[1])
+	}
+	ks := info.Keyspace()
+	if len(ks) != 2 || ks[0] != (KeyspaceInfo{Keys: 10, Expires: 2, AvgTTL: 3 * time.Second}) || ks[12].Keys != 1 {
+		t.Errorf("Keyspace = %+v", ks)
+	}
+}
+
+func TestParseInfoWithoutSection(t *testing.T) {
+	// CLUSTER INFO的格式
+	info := ParseInfo("cluster_state:ok\r\ncluster_known_nodes:6\r\n")
+	if info.Get("", "cluster_state") != "ok" || info.Int("", "cluster_known_nodes") != 6 {
+		t.Fatalf("Sections = %+v", info.Sections)
+	}
+}
+
+func TestShutdownConnectionClosed(t *testing.T) {
+	tests := []struct {
+		name    string
+		script  string
+		readErr error
+		ok      bool
+	}{
+		{"eof", "", io.EOF, true},
+		{"unexpected eof", "", io.ErrUnexpectedEOF, true},
+		{"reset", "", &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"timeout", "", &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}, false},
+		{"server error", "-ERR Errors trying to SHUTDOWN. Check logs.\r\n", nil, false},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis(tt.script)
+		fc.readErr = tt.readErr
+		err := g.ShutdownWithOptions(&ShutdownOptions{NoSave: true})
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: err = %v", tt.name, err)
+		}
+	}
+}
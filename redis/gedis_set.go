@@ -0,0 +1,101 @@
+package gedis
+
+// Set类型相关的命令
+
+// 返回新增成员的数量
+func (g *Gedis)SAdd(key string, members... interface{}) (int64, error) {
+	return g.Cmd("SADD", key, members).Int64()
+}
+
+// 返回实际移除成员的数量
+func (g *Gedis)SRem(key string, members... interface{}) (int64, error) {
+	return g.Cmd("SREM", key, members).Int64()
+}
+
+func (g *Gedis)SMembers(key string) ([]string, error) {
+	return g.Cmd("SMEMBERS", key).releaseList()
+}
+
+// 与SMembers相同，但不将成员转换成string，适合存放二进制数据的集合
+func (g *Gedis)SMembersBytes(key string) ([][]byte, error) {
+	return g.Cmd("SMEMBERS", key).releaseListBytes()
+}
+
+func (g *Gedis)SIsMember(key string, member interface{}) (bool, error) {
+	return g.Cmd("SISMEMBER", key, member).Bool()
+}
+
+// 返回值与members一一对应
+func (g *Gedis)SMIsMember(key string, members... interface{}) ([]bool, error) {
+	list, err := intList(g.Cmd("SMISMEMBER", key, members))
+	if err != nil {
+		return nil, err
+	}
+	bools := make([]bool, len(list))
+	for i, n := range list {
+		bools[i] = n == 1
+	}
+	return bools, nil
+}
+
+func (g *Gedis)SCard(key string) (int64, error) {
+	return g.Cmd("SCARD", key).Int64()
+}
+
+// 随机移除并返回一个成员，集合为空时返回ErrNil
+func (g *Gedis)SPop(key string) (string, error) {
+	return g.Cmd("SPOP", key).Str()
+}
+
+// 随机移除并返回最多count个成员
+func (g *Gedis)SPopCount(key string, count int64) ([]string, error) {
+	return g.Cmd("SPOP", key, count).releaseList()
+}
+
+// 随机返回一个成员，集合为空时返回ErrNil
+func (g *Gedis)SRandMember(key string) (string, error) {
+	return g.Cmd("SRANDMEMBER", key).Str()
+}
+
+// 随机返回count个成员，count为负数时允许重复
+func (g *Gedis)SRandMemberCount(key string, count int64) ([]string, error) {
+	return g.Cmd("SRANDMEMBER", key, count).releaseList()
+}
+
+// 将member从source移动到destination，返回是否移动成功
+func (g *Gedis)SMove(source, destination string, member interface{}) (bool, error) {
+	return g.Cmd("SMOVE", source, destination, member).Bool()
+}
+
+func (g *Gedis)SInter(keys... string) ([]string, error) {
+	return g.Cmd("SINTER", keys).releaseList()
+}
+
+func (g *Gedis)SUnion(keys... string) ([]string, error) {
+	return g.Cmd("SUNION", keys).releaseList()
+}
+
+func (g *Gedis)SDiff(keys... string) ([]string, error) {
+	return g.Cmd("SDIFF", keys).releaseList()
+}
+
+// 将交集保存到destination，返回结果集合的成员数量
+func (g *Gedis)SInterStore(destination string, keys... string) (int64, error) {
+	return g.Cmd("SINTERSTORE", destination, keys).Int64()
+}
+
+func (g *Gedis)SUnionStore(destination string, keys... string) (int64, error) {
+	return g.Cmd("SUNIONSTORE", destination, keys).Int64()
+}
+
+func (g *Gedis)SDiffStore(destination string, keys... string) (int64, error) {
+	return g.Cmd("SDIFFSTORE", destination, keys).Int64()
+}
+
+// 返回交集的成员数量，limit大于0时计数达到limit即停止
+func (g *Gedis)SInterCard(limit int64, keys... string) (int64, error) {
+	if limit > 0 {
+		return g.Cmd("SINTERCARD", len(keys), keys, "LIMIT", limit).Int64()
+	}
+	return g.Cmd("SINTERCARD", len(keys), keys).Int64()
+}
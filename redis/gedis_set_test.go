@@ -0,0 +1,54 @@
+package gedis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSInterCardLimit(t *testing.T) {
+	tests := []struct {
+		limit int64
+		want  string
+	}{
+		{0, respCommand("SINTERCARD", "2", "a", "b")},
+		{-1, respCommand("SINTERCARD", "2", "a", "b")},
+		{5, respCommand("SINTERCARD", "2", "a", "b", "LIMIT", "5")},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis(":3\r\n")
+		if n, err := g.SInterCard(tt.limit, "a", "b"); n != 3 || err != nil {
+			t.Fatalf("limit %d: SInterCard = %d, %v", tt.limit, n, err)
+		}
+		if fc.written.String() != tt.want {
+			t.Errorf("limit %d: written %q, want %q", tt.limit, fc.written.String(), tt.want)
+		}
+	}
+}
+
+func TestSetStoreCommands(t *testing.T) {
+	tests := []struct {
+		cmd   string
+		store func(g *Gedis) (int64, error)
+	}{
+		{"SINTERSTORE", func(g *Gedis) (int64, error) { return g.SInterStore("dst", "a", "b") }},
+		{"SUNIONSTORE", func(g *Gedis) (int64, error) { return g.SUnionStore("dst", "a", "b") }},
+		{"SDIFFSTORE", func(g *Gedis) (int64, error) { return g.SDiffStore("dst", "a", "b") }},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis(":2\r\n")
+		if n, err := tt.store(g); n != 2 || err != nil {
+			t.Fatalf("%s = %d, %v, want 2", tt.cmd, n, err)
+		}
+		if w := respCommand(tt.cmd, "dst", "a", "b"); fc.written.String() != w {
+			t.Errorf("%s: written %q, want %q", tt.cmd, fc.written.String(), w)
+		}
+	}
+}
+
+func TestSMIsMember(t *testing.T) {
+	g, _ := newFakeGedis(respArray(":1\r\n", ":0\r\n", ":1\r\n"))
+	got, err := g.SMIsMember("s", "a", "b", "c")
+	if err != nil || !reflect.DeepEqual(got, []bool{true, false, true}) {
+		t.Errorf("SMIsMember = %v, %v", got, err)
+	}
+}
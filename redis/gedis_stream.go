@@ -0,0 +1,666 @@
+package gedis
+
+import (
+	"errors"
+	"time"
+)
+
+// Stream类型相关的命令
+
+// Stream中的一条消息，已被删除的消息(如XREADGROUP读取历史pending消息时)Values为nil
+type XMessage struct {
+	ID     string
+	Values map[string]string
+}
+
+// XREAD/XREADGROUP返回的一个stream及其消息
+type XStream struct {
+	Stream   string
+	Messages []XMessage
+}
+
+// XREAD/XREADGROUP中的BLOCK参数使用该值表示一直阻塞(BLOCK 0)
+const BlockForever time.Duration = -1
+
+// XADD/XTRIM的裁剪方式
+type XTrimStrategy string
+
+const (
+	XTrimMaxLen XTrimStrategy = "MAXLEN"
+	XTrimMinID  XTrimStrategy = "MINID"
+)
+
+// XADD/XTRIM的裁剪参数，MaxLen与MinID只能设置其中一个；
+// Strategy为空时根据MaxLen大于0或MinID不为空确定裁剪方式，需要发送MAXLEN 0时设置Strategy为XTrimMaxLen
+type XTrimArgs struct {
+	Strategy XTrimStrategy
+	MaxLen   int64
+	MinID    string
+	// 使用 ~ 进行近似裁剪
+	Approx   bool
+	// 近似裁剪时一次最多删除的条目数，0表示使用服务端默认值
+	Limit    int64
+}
+
+func (t *XTrimArgs) args() []interface{} {
+	args := make([]interface{}, 0, 5)
+	if t == nil {
+		return args
+	}
+	strategy := t.Strategy
+	if strategy == "" {
+		if t.MaxLen > 0 {
+			strategy = XTrimMaxLen
+		} else if t.MinID != "" {
+			strategy = XTrimMinID
+		} else {
+			return args
+		}
+	}
+	args = append(args, string(strategy))
+	if t.Approx {
+		args = append(args, "~")
+	} else {
+		args = append(args, "=")
+	}
+	if strategy == XTrimMaxLen {
+		args = append(args, t.MaxLen)
+	} else {
+		args = append(args, t.MinID)
+	}
+	if t.Approx && t.Limit > 0 {
+		args = append(args, "LIMIT", t.Limit)
+	}
+	return args
+}
+
+type XAddArgs struct {
+	Stream     string
+	// stream不存在时不自动创建
+	NoMkStream bool
+	Trim       *XTrimArgs
+	// 消息ID，为空时由服务端生成("*")
+	ID         string
+	Values     map[string]interface{}
+}
+
+// 添加消息，返回消息ID；NoMkStream且stream不存在时返回ErrNil
+func (g *Gedis)XAdd(a *XAddArgs) (string, error) {
+	args := make([]interface{}, 0, 8)
+	args = append(args, a.Stream)
+	if a.NoMkStream {
+		args = append(args, "NOMKSTREAM")
+	}
+	args = append(args, a.Trim.args()...)
+	if a.ID != "" {
+		args = append(args, a.ID)
+	} else {
+		args = append(args, "*")
+	}
+	return g.Cmd("XADD", args, flattenPairs(a.Values)).Str()
+}
+
+// 返回[start, end]区间内的消息，"-"和"+"分别表示最小和最大ID
+func (g *Gedis)XRange(stream, start, end string) ([]XMessage, error) {
+	return parseXMessages(g.Cmd("XRANGE", stream, start, end))
+}
+
+func (g *Gedis)XRangeN(stream, start, end string, count int64) ([]XMessage, error) {
+	return parseXMessages(g.Cmd("XRANGE", stream, start, end, "COUNT", count))
+}
+
+// 按ID从大到小返回消息，注意参数顺序为end在前
+func (g *Gedis)XRevRange(stream, end, start string) ([]XMessage, error) {
+	return parseXMessages(g.Cmd("XREVRANGE", stream, end, start))
+}
+
+func (g *Gedis)XRevRangeN(stream, end, start string, count int64) ([]XMessage, error) {
+	return parseXMessages(g.Cmd("XREVRANGE", stream, end, start, "COUNT", count))
+}
+
+type XReadArgs struct {
+	// stream及从哪个ID之后开始读取，"$"表示只读取新消息
+	Streams map[string]string
+	Count   int64
+	// 0表示不阻塞，BlockForever表示一直阻塞
+	Block   time.Duration
+}
+
+// 读取消息，阻塞超时后返回ErrNil
+func (g *Gedis)XRead(a *XReadArgs) ([]XStream, error) {
+	args := make([]interface{}, 0, len(a.Streams) * 2 + 5)
+	if a.Count > 0 {
+		args = append(args, "COUNT", a.Count)
+	}
+	return g.xread("XREAD", a.Block, args, a.Streams)
+}
+
+type XReadGroupArgs struct {
+	Group    string
+	Consumer string
+	// stream及从哪个ID之后开始读取，">"表示读取从未投递给其它消费者的消息
+	Streams  map[string]string
+	Count    int64
+	// 0表示不阻塞，BlockForever表示一直阻塞
+	Block    time.Duration
+	// 读取的消息不加入PEL，无需XACK
+	NoAck    bool
+}
+
+// 以消费组的方式读取消息，阻塞超时后返回ErrNil
+func (g *Gedis)XReadGroup(a *XReadGroupArgs) ([]XStream, error) {
+	args := make([]interface{}, 0, len(a.Streams) * 2 + 9)
+	args = append(args, "GROUP", a.Group, a.Consumer)
+	if a.Count > 0 {
+		args = append(args, "COUNT", a.Count)
+	}
+	if a.NoAck {
+		args = append(args, "NOACK")
+	}
+	return g.xread("XREADGROUP", a.Block, args, a.Streams)
+}
+
+func (g *Gedis)xread(cmd string, block time.Duration, args []interface{}, streams map[string]string) ([]XStream, error) {
+	keys := make([]interface{}, 0, len(streams))
+	ids := make([]interface{}, 0, len(streams))
+	for k, id := range streams {
+		keys = append(keys, k)
+		ids = append(ids, id)
+	}
+	var r *Reply
+	switch {
+	case block == BlockForever:
+		r = g.BlockingCmd(0, cmd, args, "BLOCK", 0, "STREAMS", keys, ids)
+	case block > 0:
+		// 不足1毫秒的按1毫秒发送，BLOCK 0表示无限阻塞
+		r = g.BlockingCmd(block, cmd, args, "BLOCK", milliseconds(block), "STREAMS", keys, ids)
+	default:
+		r = g.Cmd(cmd, args, "STREAMS", keys, ids)
+	}
+	return parseXStreams(r)
+}
+
+// 确认消息已被处理，返回确认成功的数量
+func (g *Gedis)XAck(stream, group string, ids... string) (int64, error) {
+	return g.Cmd("XACK", stream, group, ids).Int64()
+}
+
+// 删除消息，返回删除的数量
+func (g *Gedis)XDel(stream string, ids... string) (int64, error) {
+	return g.Cmd("XDEL", stream, ids).Int64()
+}
+
+// 裁剪stream，返回删除的条目数
+func (g *Gedis)XTrim(stream string, trim *XTrimArgs) (int64, error) {
+	return g.Cmd("XTRIM", stream, trim.args()).Int64()
+}
+
+func (g *Gedis)XLen(stream string) (int64, error) {
+	return g.Cmd("XLEN", stream).Int64()
+}
+
+// XPENDING的汇总形式的返回值
+type XPending struct {
+	Count     int64
+	Lower     string
+	Higher    string
+	// 每个消费者pending的消息数
+	Consumers map[string]int64
+}
+
+// 返回消费组pending消息的汇总信息
+func (g *Gedis)XPending(stream, group string) (*XPending, error) {
+	r := g.Cmd("XPENDING", stream, group)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply || len(r.Children) != 4 {
+		return nil, errors.New("reply is not formatted as a XPENDING reply")
+	}
+	p := &XPending{Consumers: make(map[string]int64)}
+	var err error
+	if p.Count, err = r.Children[0].Int64(); err != nil {
+		return nil, err
+	}
+	// 没有pending消息时后三个元素都为nil
+	p.Lower, _ = r.Children[1].Str()
+	p.Higher, _ = r.Children[2].Str()
+	for _, c := range r.Children[3].Children {
+		if len(c.Children) != 2 {
+			return nil, errors.New("reply is not formatted as a XPENDING reply")
+		}
+		name, err := c.Children[0].Str()
+		if err != nil {
+			return nil, err
+		}
+		// 数量以字符串形式返回
+		if p.Consumers[name], err = c.Children[1].Int64(); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// XPENDING扩展形式返回的一条pending消息
+type XPendingEntry struct {
+	ID         string
+	Consumer   string
+	// 距离上一次投递的时间
+	Idle       time.Duration
+	// 被投递的次数
+	RetryCount int64
+}
+
+type XPendingExtArgs struct {
+	Stream   string
+	Group    string
+	// 只返回空闲时间大于Idle的消息，0表示不过滤
+	Idle     time.Duration
+	Start    string
+	End      string
+	Count    int64
+	// 只返回指定消费者的消息，为空时不过滤
+	Consumer string
+}
+
+// 返回pending消息的详细信息
+func (g *Gedis)XPendingExt(a *XPendingExtArgs) ([]XPendingEntry, error) {
+	args := make([]interface{}, 0, 8)
+	args = append(args, a.Stream, a.Group)
+	if a.Idle > 0 {
+		args = append(args, "IDLE", int64(a.Idle / time.Millisecond))
+	}
+	args = append(args, a.Start, a.End, a.Count)
+	if a.Consumer != "" {
+		args = append(args, a.Consumer)
+	}
+	r := g.Cmd("XPENDING", args...)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	entries := make([]XPendingEntry, len(r.Children))
+	for i, c := range r.Children {
+		if len(c.Children) != 4 {
+			return nil, errors.New("reply is not formatted as a XPENDING entry")
+		}
+		e := &entries[i]
+		var err error
+		if e.ID, err = c.Children[0].Str(); err != nil {
+			return nil, err
+		}
+		if e.Consumer, err = c.Children[1].Str(); err != nil {
+			return nil, err
+		}
+		idle, err := c.Children[2].Int64()
+		if err != nil {
+			return nil, err
+		}
+		e.Idle = time.Duration(idle) * time.Millisecond
+		if e.RetryCount, err = c.Children[3].Int64(); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+type XClaimArgs struct {
+	Stream     string
+	Group      string
+	Consumer   string
+	// 只转移空闲时间大于MinIdle的消息
+	MinIdle    time.Duration
+	IDs        []string
+
+	// 以下为可选参数，零值时不发送
+	Idle       time.Duration
+	Time       time.Time
+	RetryCount int64
+	Force      bool
+}
+
+func (a *XClaimArgs) args() []interface{} {
+	args := make([]interface{}, 0, len(a.IDs) + 12)
+	args = append(args, a.Stream, a.Group, a.Consumer, int64(a.MinIdle / time.Millisecond))
+	for _, id := range a.IDs {
+		args = append(args, id)
+	}
+	if a.Idle > 0 {
+		args = append(args, "IDLE", int64(a.Idle / time.Millisecond))
+	}
+	if !a.Time.IsZero() {
+		args = append(args, "TIME", a.Time.UnixMilli())
+	}
+	if a.RetryCount > 0 {
+		args = append(args, "RETRYCOUNT", a.RetryCount)
+	}
+	if a.Force {
+		args = append(args, "FORCE")
+	}
+	return args
+}
+
+// 将pending消息转移给指定消费者，返回转移成功的消息
+func (g *Gedis)XClaim(a *XClaimArgs) ([]XMessage, error) {
+	return parseXMessages(g.Cmd("XCLAIM", a.args()))
+}
+
+// XCLAIM ... JUSTID，只返回转移成功的消息ID，且不增加投递次数
+func (g *Gedis)XClaimJustID(a *XClaimArgs) ([]string, error) {
+	return g.Cmd("XCLAIM", a.args(), "JUSTID").List()
+}
+
+type XAutoClaimArgs struct {
+	Stream   string
+	Group    string
+	Consumer string
+	MinIdle  time.Duration
+	// 从该ID开始扫描，"0-0"表示从头开始
+	Start    string
+	Count    int64
+}
+
+func (a *XAutoClaimArgs) args() []interface{} {
+	args := []interface{}{a.Stream, a.Group, a.Consumer, int64(a.MinIdle / time.Millisecond), a.Start}
+	if a.Count > 0 {
+		args = append(args, "COUNT", a.Count)
+	}
+	return args
+}
+
+// 自动转移空闲的pending消息，返回转移的消息、下一次扫描的起始ID
+// 以及已经从stream中删除而被移出PEL的消息ID(Redis 7.0+)
+func (g *Gedis)XAutoClaim(a *XAutoClaimArgs) ([]XMessage, string, []string, error) {
+	r := g.Cmd("XAUTOCLAIM", a.args())
+	if r.Type == ErrorReply {
+		return nil, "", nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, "", nil, nil
+	}
+	if r.Type != MultiReply || len(r.Children) < 2 {
+		return nil, "", nil, errors.New("reply is not formatted as a XAUTOCLAIM reply")
+	}
+	next, err := r.Children[0].Str()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	msgs, err := parseXMessages(r.Children[1])
+	if err != nil {
+		return nil, "", nil, err
+	}
+	var deleted []string
+	if len(r.Children) > 2 {
+		if deleted, err = r.Children[2].List(); err != nil {
+			return nil, "", nil, err
+		}
+	}
+	return msgs, next, deleted, nil
+}
+
+// XAUTOCLAIM ... JUSTID
+func (g *Gedis)XAutoClaimJustID(a *XAutoClaimArgs) ([]string, string, []string, error) {
+	r := g.Cmd("XAUTOCLAIM", a.args(), "JUSTID")
+	if r.Type == ErrorReply {
+		return nil, "", nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, "", nil, nil
+	}
+	if r.Type != MultiReply || len(r.Children) < 2 {
+		return nil, "", nil, errors.New("reply is not formatted as a XAUTOCLAIM reply")
+	}
+	next, err := r.Children[0].Str()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	ids, err := r.Children[1].List()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	var deleted []string
+	if len(r.Children) > 2 {
+		if deleted, err = r.Children[2].List(); err != nil {
+			return nil, "", nil, err
+		}
+	}
+	return ids, next, deleted, nil
+}
+
+// 创建消费组，start为"$"表示只消费新消息，mkStream为true时stream不存在则自动创建
+func (g *Gedis)XGroupCreate(stream, group, start string, mkStream bool) (string, error) {
+	if mkStream {
+		return g.Cmd("XGROUP", "CREATE", stream, group, start, "MKSTREAM").Str()
+	}
+	return g.Cmd("XGROUP", "CREATE", stream, group, start).Str()
+}
+
+func (g *Gedis)XGroupSetID(stream, group, start string) (string, error) {
+	return g.Cmd("XGROUP", "SETID", stream, group, start).Str()
+}
+
+// 返回是否删除成功
+func (g *Gedis)XGroupDestroy(stream, group string) (bool, error) {
+	return g.Cmd("XGROUP", "DESTROY", stream, group).Bool()
+}
+
+// 返回是否创建成功，消费者已存在时返回false
+func (g *Gedis)XGroupCreateConsumer(stream, group, consumer string) (bool, error) {
+	return g.Cmd("XGROUP", "CREATECONSUMER", stream, group, consumer).Bool()
+}
+
+// 删除消费者，返回该消费者被删除时仍pending的消息数
+func (g *Gedis)XGroupDelConsumer(stream, group, consumer string) (int64, error) {
+	return g.Cmd("XGROUP", "DELCONSUMER", stream, group, consumer).Int64()
+}
+
+// XINFO STREAM的返回值
+type XInfoStream struct {
+	Length               int64
+	RadixTreeKeys        int64
+	RadixTreeNodes       int64
+	LastGeneratedID      string
+	MaxDeletedEntryID    string
+	EntriesAdded         int64
+	RecordedFirstEntryID string
+	Groups               int64
+	FirstEntry           *XMessage
+	LastEntry            *XMessage
+}
+
+func (g *Gedis)XInfoStream(stream string) (*XInfoStream, error) {
+	m, err := kvReplies(g.Cmd("XINFO", "STREAM", stream))
+	if err != nil {
+		return nil, err
+	}
+	info := &XInfoStream{
+		Length: kvInt(m, "length"),
+		RadixTreeKeys: kvInt(m, "radix-tree-keys"),
+		RadixTreeNodes: kvInt(m, "radix-tree-nodes"),
+		LastGeneratedID: kvStr(m, "last-generated-id"),
+		MaxDeletedEntryID: kvStr(m, "max-deleted-entry-id"),
+		EntriesAdded: kvInt(m, "entries-added"),
+		RecordedFirstEntryID: kvStr(m, "recorded-first-entry-id"),
+		Groups: kvInt(m, "groups"),
+	}
+	if r, ok := m["first-entry"]; ok && r.Type == MultiReply {
+		msg, err := parseXMessage(r)
+		if err != nil {
+			return nil, err
+		}
+		info.FirstEntry = &msg
+	}
+	if r, ok := m["last-entry"]; ok && r.Type == MultiReply {
+		msg, err := parseXMessage(r)
+		if err != nil {
+			return nil, err
+		}
+		info.LastEntry = &msg
+	}
+	return info, nil
+}
+
+// XINFO GROUPS返回的一个消费组，Lag为-1表示服务端无法计算
+type XInfoGroup struct {
+	Name            string
+	Consumers       int64
+	Pending         int64
+	LastDeliveredID string
+	EntriesRead     int64
+	Lag             int64
+}
+
+func (g *Gedis)XInfoGroups(stream string) ([]XInfoGroup, error) {
+	r := g.Cmd("XINFO", "GROUPS", stream)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	groups := make([]XInfoGroup, len(r.Children))
+	for i, c := range r.Children {
+		m, err := kvReplies(c)
+		if err != nil {
+			return nil, err
+		}
+		groups[i] = XInfoGroup{
+			Name: kvStr(m, "name"),
+			Consumers: kvInt(m, "consumers"),
+			Pending: kvInt(m, "pending"),
+			LastDeliveredID: kvStr(m, "last-delivered-id"),
+			EntriesRead: kvInt(m, "entries-read"),
+			Lag: -1,
+		}
+		if lag, ok := m["lag"]; ok && lag.Type == IntegerReply {
+			groups[i].Lag = lag.int
+		}
+	}
+	return groups, nil
+}
+
+// XINFO CONSUMERS返回的一个消费者
+type XInfoConsumer struct {
+	Name     string
+	Pending  int64
+	// 距离上一次尝试交互(读取、认领等)的时间
+	Idle     time.Duration
+	// 距离上一次成功交互的时间(Redis 7.2+)，从未成功交互或服务端不支持时为-1
+	Inactive time.Duration
+}
+
+func (g *Gedis)XInfoConsumers(stream, group string) ([]XInfoConsumer, error) {
+	r := g.Cmd("XINFO", "CONSUMERS", stream, group)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	consumers := make([]XInfoConsumer, len(r.Children))
+	for i, c := range r.Children {
+		m, err := kvReplies(c)
+		if err != nil {
+			return nil, err
+		}
+		consumers[i] = XInfoConsumer{
+			Name: kvStr(m, "name"),
+			Pending: kvInt(m, "pending"),
+			Idle: time.Duration(kvInt(m, "idle")) * time.Millisecond,
+			Inactive: -1,
+		}
+		if _, ok := m["inactive"]; ok && kvInt(m, "inactive") >= 0 {
+			consumers[i].Inactive = time.Duration(kvInt(m, "inactive")) * time.Millisecond
+		}
+	}
+	return consumers, nil
+}
+
+// 解析 [[id, [field, value, ...]], ...] 形式的消息列表
+func parseXMessages(r *Reply) ([]XMessage, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	msgs := make([]XMessage, 0, len(r.Children))
+	for _, c := range r.Children {
+		// XCLAIM等命令中已被删除的消息可能以nil返回
+		if c.Type == NilReply {
+			continue
+		}
+		msg, err := parseXMessage(c)
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func parseXMessage(r *Reply) (XMessage, error) {
+	if r.Type != MultiReply || len(r.Children) != 2 {
+		return XMessage{}, errors.New("reply is not formatted as a stream message")
+	}
+	id, err := r.Children[0].Str()
+	if err != nil {
+		return XMessage{}, err
+	}
+	msg := XMessage{ID: id}
+	if r.Children[1].Type == NilReply {
+		return msg, nil
+	}
+	if msg.Values, err = r.Children[1].Hash(); err != nil {
+		return XMessage{}, err
+	}
+	return msg, nil
+}
+
+// 解析XREAD/XREADGROUP的 [[stream, messages], ...] 形式的返回值
+func parseXStreams(r *Reply) ([]XStream, error) {
+	r = nilAsErrNil(r)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	streams := make([]XStream, 0, len(r.Children))
+	for _, c := range r.Children {
+		if len(c.Children) != 2 {
+			return nil, errors.New("reply is not formatted as a stream reply")
+		}
+		name, err := c.Children[0].Str()
+		if err != nil {
+			return nil, err
+		}
+		msgs, err := parseXMessages(c.Children[1])
+		if err != nil {
+			return nil, err
+		}
+		streams = append(streams, XStream{Stream: name, Messages: msgs})
+	}
+	return streams, nil
+}
+
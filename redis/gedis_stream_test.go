@@ -0,0 +1,128 @@
+package gedis
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseXMessages(t *testing.T) {
+	r := mustReply(t, respArray(
+		respArray(respBulk("1-0"), respArray(respBulk("f1"), respBulk("v1"), respBulk("f2"), respBulk("v2"))),
+		// XCLAIM中已被删除的消息
+		"*-1\r\n",
+		// XAUTOCLAIM/XPENDING中消息体为nil
+		respArray(respBulk("2-0"), "*-1\r\n"),
+	))
+	msgs, err := parseXMessages(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 {
+		t.Fatalf("len = %d, want 2: %+v", len(msgs), msgs)
+	}
+	if msgs[0].ID != "1-0" || msgs[0].Values["f1"] != "v1" || msgs[0].Values["f2"] != "v2" {
+		t.Errorf("msgs[0] = %+v", msgs[0])
+	}
+	if msgs[1].ID != "2-0" || msgs[1].Values != nil {
+		t.Errorf("msgs[1] = %+v", msgs[1])
+	}
+
+	for _, bad := range []string{
+		":1\r\n",
+		respArray(respArray(respBulk("1-0"))),
+		respArray(respArray(respBulk("1-0"), respArray(respBulk("f1")))),
+	} {
+		if _, err := parseXMessages(mustReply(t, bad)); err == nil {
+			t.Errorf("parseXMessages(%q) should fail", bad)
+		}
+	}
+	if _, err := parseXMessages(mustReply(t, "-ERR x\r\n")); err == nil || err.Error() != "ERR x" {
+		t.Errorf("parseXMessages(error) = %v", err)
+	}
+}
+
+func TestParseXStreams(t *testing.T) {
+	r := mustReply(t, respArray(
+		respArray(respBulk("s1"), respArray(respArray(respBulk("1-0"), respArray(respBulk("f"), respBulk("v"))))),
+	))
+	streams, err := parseXStreams(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(streams) != 1 || streams[0].Stream != "s1" || len(streams[0].Messages) != 1 {
+		t.Fatalf("streams = %+v", streams)
+	}
+	// 阻塞超时
+	if _, err := parseXStreams(mustReply(t, "*-1\r\n")); err != ErrNil {
+		t.Fatalf("timeout err = %v, want ErrNil", err)
+	}
+}
+
+func TestXReadBlock(t *testing.T) {
+	tests := []struct {
+		block time.Duration
+		want  string
+	}{
+		{0, ""},
+		{BlockForever, "$5\r\nBLOCK\r\n$1\r\n0\r\n"},
+		{500 * time.Microsecond, "$5\r\nBLOCK\r\n$1\r\n1\r\n"},
+		{1500 * time.Millisecond, "$5\r\nBLOCK\r\n$4\r\n1500\r\n"},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis("*-1\r\n")
+		g.XRead(&XReadArgs{Streams: map[string]string{"s": "$"}, Block: tt.block})
+		written := fc.written.String()
+		if tt.want == "" {
+			if strings.Contains(written, "BLOCK") {
+				t.Errorf("block %v: unexpected BLOCK in %q", tt.block, written)
+			}
+		} else if !strings.Contains(written, tt.want) {
+			t.Errorf("block %v: %q does not contain %q", tt.block, written, tt.want)
+		}
+	}
+}
+
+func TestXInfoConsumersInactive(t *testing.T) {
+	g, _ := newFakeGedis(respArray(
+		// Redis 7.2之前没有inactive
+		respArray(respBulk("name"), respBulk("c1"), respBulk("pending"), ":2\r\n", respBulk("idle"), ":100\r\n"),
+		respArray(respBulk("name"), respBulk("c2"), respBulk("pending"), ":0\r\n", respBulk("idle"), ":5\r\n", respBulk("inactive"), ":-1\r\n"),
+		respArray(respBulk("name"), respBulk("c3"), respBulk("pending"), ":0\r\n", respBulk("idle"), ":5\r\n", respBulk("inactive"), ":0\r\n"),
+	))
+	consumers, err := g.XInfoConsumers("s", "g")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []XInfoConsumer{
+		{"c1", 2, 100 * time.Millisecond, -1},
+		{"c2", 0, 5 * time.Millisecond, -1},
+		{"c3", 0, 5 * time.Millisecond, 0},
+	}
+	for i := range want {
+		if consumers[i] != want[i] {
+			t.Errorf("consumers[%d] = %+v, want %+v", i, consumers[i], want[i])
+		}
+	}
+}
+
+func TestXTrimArgs(t *testing.T) {
+	tests := []struct {
+		trim *XTrimArgs
+		want string
+	}{
+		{nil, "[]"},
+		{&XTrimArgs{}, "[]"},
+		{&XTrimArgs{MaxLen: 100}, "[MAXLEN = 100]"},
+		{&XTrimArgs{MaxLen: 100, Approx: true, Limit: 10}, "[MAXLEN ~ 100 LIMIT 10]"},
+		{&XTrimArgs{MinID: "1-0", Limit: 10}, "[MINID = 1-0]"},
+		{&XTrimArgs{Strategy: XTrimMaxLen}, "[MAXLEN = 0]"},
+		{&XTrimArgs{Strategy: XTrimMinID, MinID: "0"}, "[MINID = 0]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tt.trim.args()); got != tt.want {
+			t.Errorf("%+v.args() = %s, want %s", tt.trim, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,286 @@
+package gedis
+
+import (
+	"errors"
+	"time"
+)
+
+// String类型相关的命令
+
+// SET命令的可选参数，EX/PX/EXAT/PXAT与KeepTTL互斥，NX与XX互斥
+type SetOptions struct {
+	// 过期时间，按秒(EX)或毫秒(PX)发送
+	EX      time.Duration
+	PX      time.Duration
+
+	// 过期时间点，按秒(EXAT)或毫秒(PXAT)级Unix时间戳发送
+	EXAT    time.Time
+	PXAT    time.Time
+
+	// 只在key不存在(NX)或存在(XX)时设置
+	NX      bool
+	XX      bool
+
+	// 保留key原有的过期时间
+	KeepTTL bool
+
+	// 返回key原来的值
+	Get     bool
+}
+
+func (o *SetOptions) args() []interface{} {
+	args := make([]interface{}, 0, 4)
+	args = appendExpiration(args, o.EX, o.PX, o.EXAT, o.PXAT)
+	if o.NX {
+		args = append(args, "NX")
+	} else if o.XX {
+		args = append(args, "XX")
+	}
+	if o.KeepTTL {
+		args = append(args, "KEEPTTL")
+	}
+	if o.Get {
+		args = append(args, "GET")
+	}
+	return args
+}
+
+// GETEX命令的可选参数，Persist表示移除key的过期时间
+type GetExOptions struct {
+	EX      time.Duration
+	PX      time.Duration
+	EXAT    time.Time
+	PXAT    time.Time
+	Persist bool
+}
+
+func (o *GetExOptions) args() []interface{} {
+	args := make([]interface{}, 0, 2)
+	args = appendExpiration(args, o.EX, o.PX, o.EXAT, o.PXAT)
+	if o.Persist {
+		args = append(args, "PERSIST")
+	}
+	return args
+}
+
+// 按 EX/PX/EXAT/PXAT 的优先级追加过期参数，只取第一个被设置的值；
+// EX不是整秒时按毫秒(PX)发送，避免截断为EX 0被服务端拒绝
+func appendExpiration(args []interface{}, ex, px time.Duration, exat, pxat time.Time) []interface{} {
+	switch {
+	case ex > 0:
+		unit, n := ttlArg("EX", "PX", ex)
+		args = append(args, unit, n)
+	case px > 0:
+		args = append(args, "PX", milliseconds(px))
+	case !exat.IsZero():
+		args = append(args, "EXAT", exat.Unix())
+	case !pxat.IsZero():
+		args = append(args, "PXAT", pxat.UnixMilli())
+	}
+	return args
+}
+
+// 整秒的时长按秒级参数(sec)发送，否则按毫秒级参数(ms)发送
+func ttlArg(sec, ms string, d time.Duration) (string, int64) {
+	if d % time.Second == 0 {
+		return sec, int64(d / time.Second)
+	}
+	return ms, milliseconds(d)
+}
+
+// 转换为毫秒，不足1毫秒的正数按1毫秒计算
+func milliseconds(d time.Duration) int64 {
+	if d > 0 && d < time.Millisecond {
+		return 1
+	}
+	return int64(d / time.Millisecond)
+}
+
+// 带可选参数的SET:
+// 未设置Get时成功返回"OK"，因NX/XX条件不满足而未设置时返回ErrNil；
+// 设置Get时返回key原来的值，原来不存在时返回ErrNil
+func (g *Gedis)SetWithOptions(key string, value interface{}, opt *SetOptions) (string, error) {
+	if opt == nil {
+		return g.Set(key, value)
+	}
+	return g.Cmd("SET", key, value, opt.args()).Str()
+}
+
+// 获取key的值并设置/移除其过期时间，key不存在时返回ErrNil
+func (g *Gedis)GetEx(key string, opt *GetExOptions) (string, error) {
+	if opt == nil {
+		return g.Cmd("GETEX", key).Str()
+	}
+	return g.Cmd("GETEX", key, opt.args()).Str()
+}
+
+// 获取key的值并删除该key，key不存在时返回ErrNil
+func (g *Gedis)GetDel(key string) (string, error) {
+	return g.Cmd("GETDEL", key).Str()
+}
+
+// 设置新值并返回旧值，key原来不存在时返回ErrNil
+func (g *Gedis)GetSet(key string, value interface{}) (string, error) {
+	return g.Cmd("GETSET", key, value).Str()
+}
+
+func (g *Gedis)Incr(key string) (int64, error) {
+	return g.Cmd("INCR", key).Int64()
+}
+
+func (g *Gedis)IncrBy(key string, increment int64) (int64, error) {
+	return g.Cmd("INCRBY", key, increment).Int64()
+}
+
+func (g *Gedis)IncrByFloat(key string, increment float64) (float64, error) {
+	return g.Cmd("INCRBYFLOAT", key, increment).Float64()
+}
+
+func (g *Gedis)Decr(key string) (int64, error) {
+	return g.Cmd("DECR", key).Int64()
+}
+
+func (g *Gedis)DecrBy(key string, decrement int64) (int64, error) {
+	return g.Cmd("DECRBY", key, decrement).Int64()
+}
+
+// 批量获取多个key的值，返回值与keys一一对应，不存在的key对应的元素为nil
+func (g *Gedis)MGet(keys... string) ([][]byte, error) {
+	return g.Cmd("MGET", keys).releaseListBytes()
+}
+
+// 批量设置，成功后返回"OK"
+func (g *Gedis)MSet(pairs map[string]interface{}) (string, error) {
+	return g.Cmd("MSET", flattenPairs(pairs)).Str()
+}
+
+// 只有当所有key都不存在时才批量设置，返回是否设置成功
+func (g *Gedis)MSetNX(pairs map[string]interface{}) (bool, error) {
+	return g.Cmd("MSETNX", flattenPairs(pairs)).Bool()
+}
+
+// 追加value到key原值的末尾，返回追加后的长度
+func (g *Gedis)Append(key string, value interface{}) (int64, error) {
+	return g.Cmd("APPEND", key, value).Int64()
+}
+
+// 返回[start, end]区间内的子串，支持负数下标
+func (g *Gedis)GetRange(key string, start, end int64) (string, error) {
+	return g.Cmd("GETRANGE", key, start, end).Str()
+}
+
+// 从offset开始覆盖写入value，返回修改后的长度
+func (g *Gedis)SetRange(key string, offset int64, value interface{}) (int64, error) {
+	return g.Cmd("SETRANGE", key, offset, value).Int64()
+}
+
+func (g *Gedis)StrLen(key string) (int64, error) {
+	return g.Cmd("STRLEN", key).Int64()
+}
+
+// 返回两个key的值的最长公共子序列
+func (g *Gedis)LCS(key1, key2 string) (string, error) {
+	return g.Cmd("LCS", key1, key2).Str()
+}
+
+// 返回两个key的值的最长公共子序列的长度
+func (g *Gedis)LCSLen(key1, key2 string) (int64, error) {
+	return g.Cmd("LCS", key1, key2, "LEN").Int64()
+}
+
+// LCS ... IDX 的返回值
+type LCSMatch struct {
+	Matches []LCSMatchedPosition
+	Len     int64
+}
+
+// 一段公共子序列分别在两个值中的位置，MatchLen只在WITHMATCHLEN时有值
+type LCSMatchedPosition struct {
+	Key1     LCSPosition
+	Key2     LCSPosition
+	MatchLen int64
+}
+
+type LCSPosition struct {
+	Start int64
+	End   int64
+}
+
+// 返回最长公共子序列中每一段匹配的位置，minMatchLen为0时不限制匹配长度
+func (g *Gedis)LCSIdx(key1, key2 string, minMatchLen int64, withMatchLen bool) (*LCSMatch, error) {
+	args := []interface{}{key1, key2, "IDX"}
+	if minMatchLen > 0 {
+		args = append(args, "MINMATCHLEN", minMatchLen)
+	}
+	if withMatchLen {
+		args = append(args, "WITHMATCHLEN")
+	}
+	return parseLCSMatch(g.Cmd("LCS", args...))
+}
+
+func parseLCSMatch(r *Reply) (*LCSMatch, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply || len(r.Children) % 2 != 0 {
+		return nil, errors.New("reply is not formatted as a LCS IDX reply")
+	}
+	m := &LCSMatch{}
+	for i := 0; i < len(r.Children); i += 2 {
+		name, err := r.Children[i].Str()
+		if err != nil {
+			return nil, err
+		}
+		switch name {
+		case "matches":
+			for _, c := range r.Children[i + 1].Children {
+				if len(c.Children) < 2 {
+					return nil, errors.New("reply is not formatted as a LCS IDX reply")
+				}
+				var p LCSMatchedPosition
+				if p.Key1, err = parseLCSPosition(c.Children[0]); err != nil {
+					return nil, err
+				}
+				if p.Key2, err = parseLCSPosition(c.Children[1]); err != nil {
+					return nil, err
+				}
+				if len(c.Children) > 2 {
+					if p.MatchLen, err = c.Children[2].Int64(); err != nil {
+						return nil, err
+					}
+				}
+				m.Matches = append(m.Matches, p)
+			}
+		case "len":
+			if m.Len, err = r.Children[i + 1].Int64(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return m, nil
+}
+
+func parseLCSPosition(r *Reply) (LCSPosition, error) {
+	var p LCSPosition
+	if len(r.Children) != 2 {
+		return p, errors.New("reply is not formatted as a LCS position")
+	}
+	var err error
+	if p.Start, err = r.Children[0].Int64(); err != nil {
+		return p, err
+	}
+	p.End, err = r.Children[1].Int64()
+	return p, err
+}
+
+// 将map展开成 k1 v1 k2 v2 ... 的参数形式
+func flattenPairs(pairs map[string]interface{}) []interface{} {
+	args := make([]interface{}, 0, len(pairs) * 2)
+	for k, v := range pairs {
+		args = append(args, k, v)
+	}
+	return args
+}
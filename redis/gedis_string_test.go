@@ -0,0 +1,48 @@
+package gedis
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSetOptionsArgs(t *testing.T) {
+	at := time.Unix(1700000000, 123000000)
+	tests := []struct {
+		opt  SetOptions
+		want string
+	}{
+		{SetOptions{}, "[]"},
+		{SetOptions{EX: 10 * time.Second}, "[EX 10]"},
+		{SetOptions{EX: 500 * time.Millisecond}, "[PX 500]"},
+		{SetOptions{EX: 1500 * time.Millisecond}, "[PX 1500]"},
+		{SetOptions{PX: 250 * time.Millisecond}, "[PX 250]"},
+		{SetOptions{PX: 300 * time.Microsecond}, "[PX 1]"},
+		{SetOptions{EXAT: at}, "[EXAT 1700000000]"},
+		{SetOptions{PXAT: at}, "[PXAT 1700000000123]"},
+		{SetOptions{EX: time.Second, PX: time.Millisecond}, "[EX 1]"},
+		{SetOptions{NX: true, Get: true}, "[NX GET]"},
+		{SetOptions{XX: true, KeepTTL: true}, "[XX KEEPTTL]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tt.opt.args()); got != tt.want {
+			t.Errorf("%+v.args() = %s, want %s", tt.opt, got, tt.want)
+		}
+	}
+}
+
+func TestGetExOptionsArgs(t *testing.T) {
+	tests := []struct {
+		opt  GetExOptions
+		want string
+	}{
+		{GetExOptions{EX: 2 * time.Second}, "[EX 2]"},
+		{GetExOptions{EX: 10 * time.Millisecond}, "[PX 10]"},
+		{GetExOptions{Persist: true}, "[PERSIST]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tt.opt.args()); got != tt.want {
+			t.Errorf("%+v.args() = %s, want %s", tt.opt, got, tt.want)
+		}
+	}
+}
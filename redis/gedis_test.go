@@ -0,0 +1,124 @@
+package gedis
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"redis/resp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 不需要Redis Server的假连接：写入的命令记录在written中，读取时依次返回script中的内容，
+// script读完后返回readErr(默认为io.EOF)；repeat为true时script读完后从头开始，用于benchmark；
+// readDeadline记录最后一次设置的读超时
+type fakeConn struct {
+	script       []byte
+	off          int
+	repeat       bool
+	readErr      error
+	written      bytes.Buffer
+	closed       bool
+	readDeadline time.Time
+}
+
+func (c *fakeConn) Read(b []byte) (int, error) {
+	if c.off >= len(c.script) {
+		if !c.repeat || len(c.script) == 0 {
+			if c.readErr != nil {
+				return 0, c.readErr
+			}
+			return 0, io.EOF
+		}
+		c.off = 0
+	}
+	n := copy(b, c.script[c.off:])
+	c.off += n
+	return n, nil
+}
+
+func (c *fakeConn) Write(b []byte) (int, error) {
+	if c.repeat {
+		return len(b), nil
+	}
+	return c.written.Write(b)
+}
+
+func (c *fakeConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func (c *fakeConn) LocalAddr() net.Addr           { return nil }
+func (c *fakeConn) RemoteAddr() net.Addr          { return nil }
+func (c *fakeConn) SetDeadline(t time.Time) error { return nil }
+func (c *fakeConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline = t
+	return nil
+}
+func (c *fakeConn) SetWriteDeadline(t time.Time) error { return nil }
+
+func newFakeConnection(fc *fakeConn) *Connection {
+	return &Connection{
+		Conn:     fc,
+		reader:   bufio.NewReaderSize(fc, bufSize),
+		writeBuf: make([]byte, 0, 1024),
+	}
+}
+
+// 返回依次读到replies(RESP格式)的Gedis
+func newFakeGedis(replies ...string) (*Gedis, *fakeConn) {
+	fc := &fakeConn{script: []byte(strings.Join(replies, ""))}
+	return &Gedis{conn: newFakeConnection(fc)}, fc
+}
+
+// 将RESP格式的文本解析为Reply，用于测试各个返回值解析函数
+func mustReply(t *testing.T, s string) *Reply {
+	t.Helper()
+	m, err := resp.ReadMessage(bufio.NewReader(strings.NewReader(s)))
+	if err != nil {
+		t.Fatalf("ReadMessage(%q): %v", s, err)
+	}
+	r, err := messageToReply(m)
+	if err != nil {
+		t.Fatalf("messageToReply(%q): %v", s, err)
+	}
+	return r
+}
+
+// 编码为RESP数组，用于构造MultiReply
+func respArray(items ...string) string {
+	var b strings.Builder
+	b.WriteString("*" + strconv.Itoa(len(items)) + "\r\n")
+	for _, item := range items {
+		b.WriteString(item)
+	}
+	return b.String()
+}
+
+func respBulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+// 编码为bulk string组成的数组，如客户端发送的命令或哨兵返回的字段列表
+func respCommand(args ...string) string {
+	items := make([]string, len(args))
+	for i, a := range args {
+		items[i] = respBulk(a)
+	}
+	return respArray(items...)
+}
+
+func TestFakeGedisWritesCommand(t *testing.T) {
+	g, fc := newFakeGedis("+OK\r\n")
+	if s, err := g.Cmd("SET", "k", "v").Str(); err != nil || s != "OK" {
+		t.Fatalf("SET = %q, %v", s, err)
+	}
+	want := "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$1\r\nv\r\n"
+	if fc.written.String() != want {
+		t.Fatalf("written = %q, want %q", fc.written.String(), want)
+	}
+}
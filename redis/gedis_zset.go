@@ -0,0 +1,508 @@
+package gedis
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Sorted Set类型相关的命令
+
+// 成员及其分值
+type Z struct {
+	Member string
+	Score  float64
+}
+
+// 分值区间的边界，支持开区间及正负无穷
+type ScoreBound struct {
+	Value     float64
+	Exclusive bool
+}
+
+var (
+	NegInf = ScoreBound{Value: math.Inf(-1)}
+	PosInf = ScoreBound{Value: math.Inf(1)}
+)
+
+// 闭区间边界
+func Inclusive(score float64) ScoreBound {
+	return ScoreBound{Value: score}
+}
+
+// 开区间边界
+func Exclusive(score float64) ScoreBound {
+	return ScoreBound{Value: score, Exclusive: true}
+}
+
+func (b ScoreBound) String() string {
+	var s string
+	switch {
+	case math.IsInf(b.Value, 1):
+		return "+inf"
+	case math.IsInf(b.Value, -1):
+		return "-inf"
+	default:
+		s = strconv.FormatFloat(b.Value, 'f', -1, 64)
+	}
+	if b.Exclusive {
+		return "(" + s
+	}
+	return s
+}
+
+// 字典序区间的边界，Min/Max分别表示"-"和"+"
+type LexBound struct {
+	Value     string
+	Exclusive bool
+	Min       bool
+	Max       bool
+}
+
+var (
+	LexMin = LexBound{Min: true}
+	LexMax = LexBound{Max: true}
+)
+
+func LexInclusive(value string) LexBound {
+	return LexBound{Value: value}
+}
+
+func LexExclusive(value string) LexBound {
+	return LexBound{Value: value, Exclusive: true}
+}
+
+func (b LexBound) String() string {
+	switch {
+	case b.Min:
+		return "-"
+	case b.Max:
+		return "+"
+	case b.Exclusive:
+		return "(" + b.Value
+	default:
+		return "[" + b.Value
+	}
+}
+
+// ZADD命令的可选参数，NX与XX/GT/LT互斥
+type ZAddOptions struct {
+	NX bool
+	XX bool
+	GT bool
+	LT bool
+	// 返回值由新增成员数改为新增及分值被修改的成员数
+	CH bool
+}
+
+func (o *ZAddOptions) args() []interface{} {
+	args := make([]interface{}, 0, 3)
+	if o == nil {
+		return args
+	}
+	if o.NX {
+		args = append(args, "NX")
+	} else if o.XX {
+		args = append(args, "XX")
+	}
+	if o.GT {
+		args = append(args, "GT")
+	} else if o.LT {
+		args = append(args, "LT")
+	}
+	if o.CH {
+		args = append(args, "CH")
+	}
+	return args
+}
+
+func zArgs(members []Z) []interface{} {
+	args := make([]interface{}, 0, len(members) * 2)
+	for _, m := range members {
+		args = append(args, m.Score, m.Member)
+	}
+	return args
+}
+
+// 添加成员，返回新增(CH时为新增及修改)的成员数
+func (g *Gedis)ZAdd(key string, opt *ZAddOptions, members... Z) (int64, error) {
+	return g.Cmd("ZADD", key, opt.args(), zArgs(members)).Int64()
+}
+
+// ZADD ... INCR，返回成员的新分值，因NX/XX/GT/LT条件不满足而未执行时返回ErrNil
+func (g *Gedis)ZAddIncr(key string, opt *ZAddOptions, member Z) (float64, error) {
+	return g.Cmd("ZADD", key, opt.args(), "INCR", member.Score, member.Member).Float64()
+}
+
+func (g *Gedis)ZCard(key string) (int64, error) {
+	return g.Cmd("ZCARD", key).Int64()
+}
+
+func (g *Gedis)ZCount(key string, min, max ScoreBound) (int64, error) {
+	return g.Cmd("ZCOUNT", key, min.String(), max.String()).Int64()
+}
+
+func (g *Gedis)ZRem(key string, members... interface{}) (int64, error) {
+	return g.Cmd("ZREM", key, members).Int64()
+}
+
+func (g *Gedis)ZIncrBy(key string, increment float64, member interface{}) (float64, error) {
+	return g.Cmd("ZINCRBY", key, increment, member).Float64()
+}
+
+// 成员不存在时返回ErrNil
+func (g *Gedis)ZScore(key string, member interface{}) (float64, error) {
+	return g.Cmd("ZSCORE", key, member).Float64()
+}
+
+// 返回值与members一一对应，不存在的成员对应nil
+func (g *Gedis)ZMScore(key string, members... interface{}) ([]*float64, error) {
+	r := g.Cmd("ZMSCORE", key, members)
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	scores := make([]*float64, len(r.Children))
+	for i, c := range r.Children {
+		if c.Type == NilReply {
+			continue
+		}
+		f, err := c.Float64()
+		if err != nil {
+			return nil, err
+		}
+		scores[i] = &f
+	}
+	return scores, nil
+}
+
+// 成员不存在时返回ErrNil
+func (g *Gedis)ZRank(key string, member interface{}) (int64, error) {
+	return g.Cmd("ZRANK", key, member).Int64()
+}
+
+func (g *Gedis)ZRevRank(key string, member interface{}) (int64, error) {
+	return g.Cmd("ZREVRANK", key, member).Int64()
+}
+
+// ZRANK ... WITHSCORE，返回排名及分值，成员不存在时返回ErrNil
+func (g *Gedis)ZRankWithScore(key string, member interface{}) (int64, float64, error) {
+	return parseRankWithScore(g.Cmd("ZRANK", key, member, "WITHSCORE"))
+}
+
+func (g *Gedis)ZRevRankWithScore(key string, member interface{}) (int64, float64, error) {
+	return parseRankWithScore(g.Cmd("ZREVRANK", key, member, "WITHSCORE"))
+}
+
+func parseRankWithScore(r *Reply) (int64, float64, error) {
+	r = nilAsErrNil(r)
+	if r.Type == ErrorReply {
+		return 0, 0, r.Err
+	}
+	if r.Type == QueuedReply {
+		return 0, 0, nil
+	}
+	if r.Type != MultiReply || len(r.Children) != 2 {
+		return 0, 0, errors.New("reply is not formatted as a rank with score")
+	}
+	rank, err := r.Children[0].Int64()
+	if err != nil {
+		return 0, 0, err
+	}
+	score, err := r.Children[1].Float64()
+	return rank, score, err
+}
+
+// ZRANGE的查询方式：按下标、按分值或按字典序
+type ZRangeBy int8
+
+const (
+	ZRangeByIndex ZRangeBy = iota
+	ZRangeByScore
+	ZRangeByLex
+)
+
+// 统一的ZRANGE查询参数，根据By的不同，使用Start/Stop、Min/Max或LexMin/LexMax作为区间
+type ZRangeArgs struct {
+	Key    string
+	By     ZRangeBy
+
+	// ZRangeByIndex
+	Start  int64
+	Stop   int64
+
+	// ZRangeByScore
+	Min    ScoreBound
+	Max    ScoreBound
+
+	// ZRangeByLex
+	LexMin LexBound
+	LexMax LexBound
+
+	Rev    bool
+
+	// Count不为0时发送LIMIT offset count，只对BYSCORE/BYLEX有效
+	Offset int64
+	Count  int64
+}
+
+// 组装区间及BYSCORE/BYLEX/REV/LIMIT参数，REV时区间需要按从大到小的顺序发送
+func (z *ZRangeArgs) args() []interface{} {
+	args := make([]interface{}, 0, 8)
+	switch z.By {
+	case ZRangeByScore:
+		if z.Rev {
+			args = append(args, z.Max.String(), z.Min.String(), "BYSCORE")
+		} else {
+			args = append(args, z.Min.String(), z.Max.String(), "BYSCORE")
+		}
+	case ZRangeByLex:
+		if z.Rev {
+			args = append(args, z.LexMax.String(), z.LexMin.String(), "BYLEX")
+		} else {
+			args = append(args, z.LexMin.String(), z.LexMax.String(), "BYLEX")
+		}
+	default:
+		args = append(args, z.Start, z.Stop)
+	}
+	if z.Rev {
+		args = append(args, "REV")
+	}
+	if z.Count != 0 && z.By != ZRangeByIndex {
+		args = append(args, "LIMIT", z.Offset, z.Count)
+	}
+	return args
+}
+
+func (g *Gedis)ZRange(z *ZRangeArgs) ([]string, error) {
+	return g.Cmd("ZRANGE", z.Key, z.args()).releaseList()
+}
+
+func (g *Gedis)ZRangeWithScores(z *ZRangeArgs) ([]Z, error) {
+	return parseZList(g.Cmd("ZRANGE", z.Key, z.args(), "WITHSCORES"))
+}
+
+// 将查询结果保存到destination，返回结果的成员数
+func (g *Gedis)ZRangeStore(destination string, z *ZRangeArgs) (int64, error) {
+	return g.Cmd("ZRANGESTORE", destination, z.Key, z.args()).Int64()
+}
+
+// ZUNION/ZINTER的聚合方式
+type ZAggregate string
+
+const (
+	ZAggregateSum ZAggregate = "SUM"
+	ZAggregateMin ZAggregate = "MIN"
+	ZAggregateMax ZAggregate = "MAX"
+)
+
+// ZUNION/ZINTER/ZDIFF及其STORE形式的参数，Weights为空时不发送，Aggregate为空时使用服务端默认的SUM
+type ZStore struct {
+	Keys      []string
+	Weights   []float64
+	Aggregate ZAggregate
+}
+
+func (z *ZStore) args() []interface{} {
+	args := make([]interface{}, 0, len(z.Keys) + len(z.Weights) + 4)
+	args = append(args, len(z.Keys))
+	for _, k := range z.Keys {
+		args = append(args, k)
+	}
+	if len(z.Weights) > 0 {
+		args = append(args, "WEIGHTS")
+		for _, w := range z.Weights {
+			args = append(args, w)
+		}
+	}
+	if z.Aggregate != "" {
+		args = append(args, "AGGREGATE", string(z.Aggregate))
+	}
+	return args
+}
+
+func (g *Gedis)ZUnion(z *ZStore) ([]string, error) {
+	return g.Cmd("ZUNION", z.args()).releaseList()
+}
+
+func (g *Gedis)ZUnionWithScores(z *ZStore) ([]Z, error) {
+	return parseZList(g.Cmd("ZUNION", z.args(), "WITHSCORES"))
+}
+
+func (g *Gedis)ZUnionStore(destination string, z *ZStore) (int64, error) {
+	return g.Cmd("ZUNIONSTORE", destination, z.args()).Int64()
+}
+
+func (g *Gedis)ZInter(z *ZStore) ([]string, error) {
+	return g.Cmd("ZINTER", z.args()).releaseList()
+}
+
+func (g *Gedis)ZInterWithScores(z *ZStore) ([]Z, error) {
+	return parseZList(g.Cmd("ZINTER", z.args(), "WITHSCORES"))
+}
+
+func (g *Gedis)ZInterStore(destination string, z *ZStore) (int64, error) {
+	return g.Cmd("ZINTERSTORE", destination, z.args()).Int64()
+}
+
+// ZDIFF不支持WEIGHTS/AGGREGATE，只使用keys
+func (g *Gedis)ZDiff(keys... string) ([]string, error) {
+	return g.Cmd("ZDIFF", len(keys), keys).releaseList()
+}
+
+func (g *Gedis)ZDiffWithScores(keys... string) ([]Z, error) {
+	return parseZList(g.Cmd("ZDIFF", len(keys), keys, "WITHSCORES"))
+}
+
+func (g *Gedis)ZDiffStore(destination string, keys... string) (int64, error) {
+	return g.Cmd("ZDIFFSTORE", destination, len(keys), keys).Int64()
+}
+
+// 弹出最多count个分值最小的成员
+func (g *Gedis)ZPopMin(key string, count int64) ([]Z, error) {
+	return parseZList(g.Cmd("ZPOPMIN", key, count))
+}
+
+// 弹出最多count个分值最大的成员
+func (g *Gedis)ZPopMax(key string, count int64) ([]Z, error) {
+	return parseZList(g.Cmd("ZPOPMAX", key, count))
+}
+
+// 阻塞版本的ZPOPMIN，返回成员所在的key及成员，超时后返回ErrNil
+func (g *Gedis)BZPopMin(timeout time.Duration, keys... string) (string, Z, error) {
+	return parseKeyZ(g.BlockingCmd(timeout, "BZPOPMIN", keys, formatTimeout(timeout)))
+}
+
+func (g *Gedis)BZPopMax(timeout time.Duration, keys... string) (string, Z, error) {
+	return parseKeyZ(g.BlockingCmd(timeout, "BZPOPMAX", keys, formatTimeout(timeout)))
+}
+
+// ZMPOP/BZMPOP弹出的一端
+type ZPopOrder string
+
+const (
+	ZPopMinOrder ZPopOrder = "MIN"
+	ZPopMaxOrder ZPopOrder = "MAX"
+)
+
+// 从第一个非空的有序集合中弹出最多count个成员，返回该集合的key及成员，都为空时返回ErrNil
+func (g *Gedis)ZMPop(order ZPopOrder, count int64, keys... string) (string, []Z, error) {
+	return parseKeyZList(g.Cmd("ZMPOP", len(keys), keys, string(order), "COUNT", count))
+}
+
+// 阻塞版本的ZMPOP，超时后返回ErrNil
+func (g *Gedis)BZMPop(timeout time.Duration, order ZPopOrder, count int64, keys... string) (string, []Z, error) {
+	return parseKeyZList(g.BlockingCmd(timeout, "BZMPOP", formatTimeout(timeout), len(keys), keys, string(order), "COUNT", count))
+}
+
+// 随机返回一个成员，集合为空时返回ErrNil
+func (g *Gedis)ZRandMember(key string) (string, error) {
+	return g.Cmd("ZRANDMEMBER", key).Str()
+}
+
+// 随机返回count个成员，count为负数时允许重复
+func (g *Gedis)ZRandMemberCount(key string, count int64) ([]string, error) {
+	return g.Cmd("ZRANDMEMBER", key, count).releaseList()
+}
+
+func (g *Gedis)ZRandMemberWithScores(key string, count int64) ([]Z, error) {
+	return parseZList(g.Cmd("ZRANDMEMBER", key, count, "WITHSCORES"))
+}
+
+// 解析 member score member score ... 形式的返回值，
+// 同时兼容RESP3下 [[member, score], ...] 的嵌套形式
+func parseZList(r *Reply) ([]Z, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	if len(r.Children) > 0 && r.Children[0].Type == MultiReply {
+		zs := make([]Z, len(r.Children))
+		for i, c := range r.Children {
+			z, err := parseZ(c.Children)
+			if err != nil {
+				return nil, err
+			}
+			zs[i] = z
+		}
+		return zs, nil
+	}
+	if len(r.Children) % 2 != 0 {
+		return nil, errors.New("reply has odd number of children")
+	}
+	zs := make([]Z, len(r.Children) / 2)
+	for i := range zs {
+		z, err := parseZ(r.Children[i * 2 : i * 2 + 2])
+		if err != nil {
+			return nil, err
+		}
+		zs[i] = z
+	}
+	return zs, nil
+}
+
+func parseZ(rs []*Reply) (Z, error) {
+	if len(rs) != 2 {
+		return Z{}, errors.New("reply is not formatted as a member score pair")
+	}
+	member, err := rs[0].Str()
+	if err != nil {
+		return Z{}, err
+	}
+	score, err := rs[1].Float64()
+	if err != nil {
+		return Z{}, err
+	}
+	return Z{Member: member, Score: score}, nil
+}
+
+// 解析 [key, member, score] 形式的返回值
+func parseKeyZ(r *Reply) (string, Z, error) {
+	r = nilAsErrNil(r)
+	if r.Type == ErrorReply {
+		return "", Z{}, r.Err
+	}
+	if r.Type == QueuedReply {
+		return "", Z{}, nil
+	}
+	if r.Type != MultiReply || len(r.Children) != 3 {
+		return "", Z{}, errors.New("reply is not formatted as a key member score reply")
+	}
+	key, err := r.Children[0].Str()
+	if err != nil {
+		return "", Z{}, err
+	}
+	z, err := parseZ(r.Children[1:])
+	return key, z, err
+}
+
+// 解析 [key, [[member, score], ...]] 形式的返回值
+func parseKeyZList(r *Reply) (string, []Z, error) {
+	r = nilAsErrNil(r)
+	if r.Type == ErrorReply {
+		return "", nil, r.Err
+	}
+	if r.Type == QueuedReply {
+		return "", nil, nil
+	}
+	if r.Type != MultiReply || len(r.Children) != 2 {
+		return "", nil, errors.New("reply is not formatted as a key members reply")
+	}
+	key, err := r.Children[0].Str()
+	if err != nil {
+		return "", nil, err
+	}
+	zs, err := parseZList(r.Children[1])
+	return key, zs, err
+}
@@ -0,0 +1,97 @@
+package gedis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestScoreBoundString(t *testing.T) {
+	tests := []struct {
+		b    ScoreBound
+		want string
+	}{
+		{Inclusive(1.5), "1.5"},
+		{Exclusive(2), "(2"},
+		{Inclusive(-3), "-3"},
+		{NegInf, "-inf"},
+		{PosInf, "+inf"},
+		{ScoreBound{Value: PosInf.Value, Exclusive: true}, "+inf"},
+	}
+	for _, tt := range tests {
+		if got := tt.b.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestLexBoundString(t *testing.T) {
+	tests := []struct {
+		b    LexBound
+		want string
+	}{
+		{LexMin, "-"},
+		{LexMax, "+"},
+		{LexInclusive("a"), "[a"},
+		{LexExclusive("b"), "(b"},
+		{LexInclusive(""), "["},
+	}
+	for _, tt := range tests {
+		if got := tt.b.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestZRangeArgs(t *testing.T) {
+	tests := []struct {
+		z    ZRangeArgs
+		want string
+	}{
+		{ZRangeArgs{Start: 0, Stop: -1}, "[0 -1]"},
+		{ZRangeArgs{Start: 0, Stop: -1, Rev: true}, "[0 -1 REV]"},
+		// 按索引查询时不发送LIMIT
+		{ZRangeArgs{Start: 0, Stop: 10, Offset: 1, Count: 2}, "[0 10]"},
+		{ZRangeArgs{By: ZRangeByScore, Min: Inclusive(1), Max: Exclusive(5)}, "[1 (5 BYSCORE]"},
+		{ZRangeArgs{By: ZRangeByScore, Min: NegInf, Max: PosInf, Rev: true}, "[+inf -inf BYSCORE REV]"},
+		{ZRangeArgs{By: ZRangeByScore, Min: NegInf, Max: PosInf, Offset: 10, Count: 5}, "[-inf +inf BYSCORE LIMIT 10 5]"},
+		{ZRangeArgs{By: ZRangeByLex, LexMin: LexInclusive("a"), LexMax: LexExclusive("c")}, "[[a (c BYLEX]"},
+		{ZRangeArgs{By: ZRangeByLex, LexMin: LexMin, LexMax: LexMax, Rev: true, Count: -1}, "[+ - BYLEX REV LIMIT 0 -1]"},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprint(tt.z.args()); got != tt.want {
+			t.Errorf("%+v.args() = %s, want %s", tt.z, got, tt.want)
+		}
+	}
+}
+
+func TestParseZList(t *testing.T) {
+	want := []Z{{Member: "a", Score: 1}, {Member: "b", Score: 2.5}}
+	tests := []struct {
+		name  string
+		reply string
+		want  []Z
+	}{
+		{"flat", respArray(respBulk("a"), respBulk("1"), respBulk("b"), respBulk("2.5")), want},
+		{"nested", respArray(respArray(respBulk("a"), respBulk("1")), respArray(respBulk("b"), respBulk("2.5"))), want},
+		{"empty", respArray(), []Z{}},
+	}
+	for _, tt := range tests {
+		got, err := parseZList(mustReply(t, tt.reply))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: parseZList = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+
+	bad := []string{
+		respArray(respBulk("a"), respBulk("1"), respBulk("b")),
+		respArray(respArray(respBulk("a"))),
+		respArray(respBulk("a"), respBulk("x")),
+		respBulk("a"),
+	}
+	for _, s := range bad {
+		if got, err := parseZList(mustReply(t, s)); err == nil {
+			t.Errorf("parseZList(%q) = %v, want an error", s, got)
+		}
+	}
+}
@@ -1,5 +1,9 @@
 package gedis
 
+import (
+	"strconv"
+)
+
 const LocalHost string = "localhost"
 
 type HostAndPort struct {
@@ -24,11 +28,11 @@ func (hap *HostAndPort)Equal(obj interface{}) bool {
 }
 
 func (hap *HostAndPort)Str() string {
-	return hap.host + ":" + hap.port
+	return hap.host + ":" + strconv.Itoa(hap.port)
 }
 
 func convertHost(host string) string {
-	if "127.0.0.1" == host || "::1" {
+	if "127.0.0.1" == host || "::1" == host {
 		return LocalHost
 	}
 	return host
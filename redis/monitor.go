@@ -0,0 +1,250 @@
+package gedis
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MONITOR输出的一条命令
+type MonitorEvent struct {
+	Time       time.Time
+	DB         int
+	// 发送命令的客户端地址，由Lua脚本执行的命令为"lua"
+	ClientAddr string
+	// 命令名，保持客户端发送时的大小写
+	Command    string
+	// 已还原转义的参数
+	Args       []string
+	// 原始的一行输出
+	Raw        string
+}
+
+// 在独占的Gedis上执行MONITOR，将服务端输出的每一行解析为MonitorEvent后通过Events发送:
+//
+//	m, err := NewMonitor(g, MonitorKeyPrefixFilter("order:"))
+//	for e := range m.Events() {
+//		fmt.Println(e.Command, e.Args)
+//	}
+//
+// MONITOR之后连接不能再执行其它命令，Stop时连接会被关闭；从连接池获取的连接由连接池丢弃并释放其占用的位置
+type Monitor struct {
+	gedis        *Gedis
+	filter       func(*MonitorEvent) bool
+	// 只由读取的goroutine写入，在events关闭之后读取
+	err          error
+
+	events       chan *MonitorEvent
+	closeChannel chan struct{}
+	stopOnce     sync.Once
+}
+
+// filter为nil时不过滤
+func NewMonitor(g *Gedis, filter func(*MonitorEvent) bool) (*Monitor, error) {
+	s, err := g.Cmd("MONITOR").Str()
+	if err != nil {
+		return nil, err
+	}
+	if s != "OK" {
+		return nil, errors.New("unexpected MONITOR reply: " + s)
+	}
+	m := &Monitor{
+		gedis: g,
+		filter: filter,
+		events: make(chan *MonitorEvent, 64),
+		closeChannel: make(chan struct{}),
+	}
+	m.start()
+	return m, nil
+}
+
+func (m *Monitor)start() {
+	go func() {
+		defer close(m.events)
+		for {
+			// MONITOR的输出没有间隔上限，不设置读超时
+			r := m.gedis.ReadReplyBlocking(0)
+			if r.Type == ErrorReply {
+				// Stop关闭连接导致的读取错误不作为错误返回
+				select {
+				case <-m.closeChannel:
+				default:
+					m.err = r.Err
+				}
+				return
+			}
+			line, err := r.Str()
+			if err != nil {
+				m.err = err
+				return
+			}
+			e, err := ParseMonitorLine(line)
+			if err != nil {
+				m.err = err
+				return
+			}
+			if m.filter != nil && !m.filter(e) {
+				continue
+			}
+			select {
+			case m.events <- e:
+			case <-m.closeChannel:
+				return
+			}
+		}
+	}()
+}
+
+// 接收事件的channel，Stop或读取出错后被关闭
+func (m *Monitor)Events() <-chan *MonitorEvent {
+	return m.events
+}
+
+// 返回导致Events被关闭的错误，调用Stop导致的关闭返回nil；只应在Events被关闭后调用
+func (m *Monitor)Err() error {
+	return m.err
+}
+
+// 停止接收并关闭连接，可以重复调用
+func (m *Monitor)Stop() {
+	m.stopOnce.Do(func() {
+		close(m.closeChannel)
+		// 先关闭socket使读取的goroutine返回，已关闭的连接在Close时不会被放回连接池
+		m.gedis.conn.Close()
+		m.gedis.Close()
+	})
+}
+
+// 解析MONITOR输出的一行，格式为:
+//
+//	1339518083.107412 [0 127.0.0.1:60866] "set" "key" "va\"lue"
+func ParseMonitorLine(line string) (*MonitorEvent, error) {
+	e := &MonitorEvent{Raw: line}
+	sp := strings.IndexByte(line, ' ')
+	if sp < 0 {
+		return nil, errors.New("reply is not formatted as a MONITOR line")
+	}
+	sec, usec, _ := strings.Cut(line[:sp], ".")
+	s, err := strconv.ParseInt(sec, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	us, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	e.Time = time.Unix(s, us * int64(time.Microsecond))
+
+	rest := line[sp + 1:]
+	if len(rest) == 0 || rest[0] != '[' {
+		return nil, errors.New("reply is not formatted as a MONITOR line")
+	}
+	// IPv6地址的格式为[::1]:port，不能直接查找第一个']'
+	end := strings.Index(rest, "] ")
+	if end < 0 {
+		return nil, errors.New("reply is not formatted as a MONITOR line")
+	}
+	db, addr, ok := strings.Cut(rest[1:end], " ")
+	if !ok {
+		return nil, errors.New("reply is not formatted as a MONITOR line")
+	}
+	if e.DB, err = strconv.Atoi(db); err != nil {
+		return nil, err
+	}
+	e.ClientAddr = addr
+
+	args, err := splitMonitorArgs(rest[end + 1:])
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("reply is not formatted as a MONITOR line")
+	}
+	e.Command = args[0]
+	e.Args = args[1:]
+	return e, nil
+}
+
+// 拆分以空格分隔的带引号参数，并还原服务端的转义(\" \\ \n \r \t \a \b \xHH)
+func splitMonitorArgs(s string) ([]string, error) {
+	args := make([]string, 0, 4)
+	i := 0
+	for {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		if i >= len(s) {
+			return args, nil
+		}
+		if s[i] != '"' {
+			return nil, errors.New("MONITOR argument is not quoted")
+		}
+		i++
+		var b strings.Builder
+		closed := false
+		for i < len(s) && !closed {
+			c := s[i]
+			switch {
+			case c == '"':
+				closed = true
+				i++
+			case c == '\\' && i + 1 < len(s):
+				i++
+				switch s[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 'r':
+					b.WriteByte('\r')
+				case 't':
+					b.WriteByte('\t')
+				case 'a':
+					b.WriteByte('\a')
+				case 'b':
+					b.WriteByte('\b')
+				case 'x':
+					if i + 2 >= len(s) {
+						return nil, errors.New("invalid escape in MONITOR argument")
+					}
+					n, err := strconv.ParseUint(s[i + 1:i + 3], 16, 8)
+					if err != nil {
+						return nil, err
+					}
+					b.WriteByte(byte(n))
+					i += 2
+				default:
+					// \" 和 \\
+					b.WriteByte(s[i])
+				}
+				i++
+			default:
+				b.WriteByte(c)
+				i++
+			}
+		}
+		if !closed {
+			return nil, errors.New("unterminated MONITOR argument")
+		}
+		args = append(args, b.String())
+	}
+}
+
+// 只保留指定命令(不区分大小写)的过滤器
+func MonitorCommandFilter(commands... string) func(*MonitorEvent) bool {
+	set := make(map[string]struct{}, len(commands))
+	for _, c := range commands {
+		set[strings.ToUpper(c)] = struct{}{}
+	}
+	return func(e *MonitorEvent) bool {
+		_, ok := set[strings.ToUpper(e.Command)]
+		return ok
+	}
+}
+
+// 只保留第一个参数(通常为key)以prefix开头的命令的过滤器
+func MonitorKeyPrefixFilter(prefix string) func(*MonitorEvent) bool {
+	return func(e *MonitorEvent) bool {
+		return len(e.Args) > 0 && strings.HasPrefix(e.Args[0], prefix)
+	}
+}
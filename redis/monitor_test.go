@@ -0,0 +1,211 @@
+package gedis
+
+import (
+	"bufio"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseMonitorLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		db      int
+		addr    string
+		command string
+		args    []string
+	}{
+		{`1339518083.107412 [0 127.0.0.1:60866] "keys" "*"`, 0, "127.0.0.1:60866", "keys", []string{"*"}},
+		{`1339518087.877697 [0 lua] "set" "foo" "bar"`, 0, "lua", "set", []string{"foo", "bar"}},
+		{`1339518099.363765 [3 unix:/tmp/redis.sock] "PING"`, 3, "unix:/tmp/redis.sock", "PING", []string{}},
+		{`1339518099.363765 [0 [::1]:52000] "get" "k"`, 0, "[::1]:52000", "get", []string{"k"}},
+		{`1339518099.000001 [15 10.0.0.1:1] "set" "va\"lue" "a\\b"`, 15, "10.0.0.1:1", "set", []string{`va"lue`, `a\b`}},
+		{`1339518099.000001 [0 10.0.0.1:1] "set" "bin\x00\xff" "l1\r\nl2\t"`, 0, "10.0.0.1:1", "set", []string{"bin\x00\xff", "l1\r\nl2\t"}},
+		{`1339518099.000001 [0 10.0.0.1:1] "set" "" "a b"`, 0, "10.0.0.1:1", "set", []string{"", "a b"}},
+	}
+	for _, tt := range tests {
+		e, err := ParseMonitorLine(tt.line)
+		if err != nil {
+			t.Errorf("ParseMonitorLine(%q): %v", tt.line, err)
+			continue
+		}
+		if e.DB != tt.db || e.ClientAddr != tt.addr || e.Command != tt.command || !reflect.DeepEqual(e.Args, tt.args) || e.Raw != tt.line {
+			t.Errorf("ParseMonitorLine(%q) = %+v", tt.line, e)
+		}
+	}
+
+	e, _ := ParseMonitorLine(`1339518083.107412 [0 lua] "ping"`)
+	if !e.Time.Equal(time.Unix(1339518083, 107412000)) {
+		t.Errorf("Time = %v", e.Time)
+	}
+}
+
+func TestParseMonitorLineInvalid(t *testing.T) {
+	for _, line := range []string{
+		"",
+		"OK",
+		`abc.1 [0 lua] "ping"`,
+		`1339518083.107412 0 lua "ping"`,
+		`1339518083.107412 [0 lua "ping"`,
+		`1339518083.107412 [lua] "ping"`,
+		`1339518083.107412 [x lua] "ping"`,
+		`1339518083.107412 [0 lua]`,
+		`1339518083.107412 [0 lua] ping`,
+		`1339518083.107412 [0 lua] "ping`,
+	} {
+		if e, err := ParseMonitorLine(line); err == nil {
+			t.Errorf("ParseMonitorLine(%q) = %+v, want error", line, e)
+		}
+	}
+}
+
+func TestSplitMonitorArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+		ok   bool
+	}{
+		{``, []string{}, true},
+		{` "a"  "b" `, []string{"a", "b"}, true},
+		{`"\x41\x7a"`, []string{"Az"}, true},
+		{`"\xe4\xb8\xad"`, []string{"中"}, true},
+		{`"\"quoted\""`, []string{`"quoted"`}, true},
+		{`"\a\b"`, []string{"\a\b"}, true},
+		{`"\x4"`, nil, false},
+		{`"\xzz"`, nil, false},
+		{`"open`, nil, false},
+		{`bare`, nil, false},
+	}
+	for _, tt := range tests {
+		args, err := splitMonitorArgs(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("splitMonitorArgs(%q) err = %v", tt.in, err)
+			continue
+		}
+		if tt.ok && !reflect.DeepEqual(args, tt.want) {
+			t.Errorf("splitMonitorArgs(%q) = %q, want %q", tt.in, args, tt.want)
+		}
+	}
+}
+
+func TestMonitorFilters(t *testing.T) {
+	e := &MonitorEvent{Command: "set", Args: []string{"order:1", "v"}}
+	if !MonitorCommandFilter("SET", "GET")(e) || MonitorCommandFilter("DEL")(e) {
+		t.Error("MonitorCommandFilter")
+	}
+	if !MonitorKeyPrefixFilter("order:")(e) || MonitorKeyPrefixFilter("user:")(e) {
+		t.Error("MonitorKeyPrefixFilter")
+	}
+	if MonitorKeyPrefixFilter("")(&MonitorEvent{Command: "ping"}) {
+		t.Error("MonitorKeyPrefixFilter matched a command without args")
+	}
+}
+
+// 通过net.Pipe模拟服务端：读取MONITOR命令后回复OK，再依次输出lines
+func newPipeGedis(lines ...string) (*Gedis, net.Conn) {
+	client, server := net.Pipe()
+	go func() {
+		buf := make([]byte, 1024)
+		if _, err := server.Read(buf); err != nil {
+			return
+		}
+		server.Write([]byte("+OK\r\n"))
+		for _, line := range lines {
+			if _, err := server.Write([]byte("+" + line + "\r\n")); err != nil {
+				return
+			}
+		}
+	}()
+	return &Gedis{conn: &Connection{Conn: client, reader: bufio.NewReader(client)}}, server
+}
+
+func newPipeMonitor(t *testing.T, filter func(*MonitorEvent) bool, lines ...string) (*Monitor, net.Conn) {
+	t.Helper()
+	g, server := newPipeGedis(lines...)
+	m, err := NewMonitor(g, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m, server
+}
+
+func TestMonitorStop(t *testing.T) {
+	m, server := newPipeMonitor(t, MonitorCommandFilter("set"),
+		`1.000001 [0 127.0.0.1:1] "get" "a"`,
+		`1.000002 [0 127.0.0.1:1] "set" "a" "1"`,
+	)
+	defer server.Close()
+	e := <-m.Events()
+	if e == nil || e.Command != "set" || e.Args[0] != "a" {
+		t.Fatalf("event = %+v", e)
+	}
+	m.Stop()
+	m.Stop()
+	for range m.Events() {
+	}
+	if m.Err() != nil {
+		t.Fatalf("Err after Stop = %v", m.Err())
+	}
+}
+
+func TestMonitorServerClosed(t *testing.T) {
+	m, server := newPipeMonitor(t, nil, `1.000001 [0 lua] "ping"`)
+	if e := <-m.Events(); e == nil || e.ClientAddr != "lua" {
+		t.Fatalf("event = %+v", e)
+	}
+	server.Close()
+	for range m.Events() {
+	}
+	if m.Err() == nil {
+		t.Fatal("Err is nil after the server closed the connection")
+	}
+	m.Stop()
+}
+
+func TestMonitorBadLine(t *testing.T) {
+	m, server := newPipeMonitor(t, nil, "garbage")
+	defer server.Close()
+	for range m.Events() {
+	}
+	if m.Err() == nil {
+		t.Fatal("Err is nil after an unparsable line")
+	}
+	m.Stop()
+}
+
+func TestMonitorStopReleasesPooledConnection(t *testing.T) {
+	var servers []net.Conn
+	builder := func(host string, port int) (*Gedis, error) {
+		g, server := newPipeGedis(`1.000001 [0 lua] "ping"`)
+		servers = append(servers, server)
+		return g, nil
+	}
+	p, err := NewGedisPoolWithConfig("localhost", 6379, PoolConfig{MaxActive: 1}, builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		p.Close()
+		for _, server := range servers {
+			server.Close()
+		}
+	}()
+	g, err := p.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	m, err := NewMonitor(g, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-m.Events()
+	m.Stop()
+	if p.pool.owns(g) {
+		t.Error("stopped monitor connection is still owned by the pool")
+	}
+	// 连接被丢弃后释放了MaxActive的位置
+	if _, err := p.Get(); err != nil {
+		t.Fatalf("Get after Stop: %v", err)
+	}
+}
@@ -0,0 +1,121 @@
+package gedis
+
+import (
+	"errors"
+)
+
+// 入队时返回的占位Reply，不可修改
+var queuedReply = &Reply{Type: QueuedReply}
+
+// WATCH的key被修改导致EXEC没有执行时返回该错误
+var ErrTxAborted = errors.New("gedis: transaction aborted")
+
+// 在管道或事务中调用不读取返回值的方法(Send、ClientReply)时返回该错误
+var ErrNotQueueable = errors.New("gedis: command can not be queued in pipeline or transaction")
+
+// 管道：命令方法只将命令入队并返回零值，Exec时一次性发送并按顺序返回每个命令的Reply:
+//
+//	p := g.Pipeline()
+//	p.Incr("counter")
+//	p.Expire("counter", time.Hour, ExpireAlways)
+//	replies, err := p.Exec()
+//
+// 管道与创建它的Gedis共用连接，Exec之前不要在该Gedis上执行其它命令，
+// 该Gedis被Close还回连接池时没有Exec的命令会被丢弃；
+// 阻塞命令在管道中不会延长读超时
+type Pipeline struct {
+	*Gedis
+}
+
+func (g *Gedis)Pipeline() *Pipeline {
+	return &Pipeline{&Gedis{conn: g.conn, queued: true}}
+}
+
+// 队列中的命令数
+func (p *Pipeline)Len() int {
+	return p.conn.Pending()
+}
+
+// 发送队列中的命令，replies与命令一一对应；err为第一个出错命令的错误
+func (p *Pipeline)Exec() ([]*Reply, error) {
+	replies := p.conn.Flush()
+	return replies, firstError(replies)
+}
+
+// 丢弃队列中的命令
+func (p *Pipeline)Discard() {
+	p.conn.Discard()
+}
+
+// 丢弃队列中的命令，不会关闭连接，连接由创建管道的Gedis管理
+func (p *Pipeline)Close() {
+	p.Discard()
+}
+
+// 事务：与Pipeline相同，但Exec时将队列中的命令包裹在MULTI/EXEC中原子地执行
+type Tx struct {
+	*Gedis
+}
+
+func (g *Gedis)TxPipeline() *Tx {
+	return &Tx{&Gedis{conn: g.conn, queued: true}}
+}
+
+func (t *Tx)Len() int {
+	return t.conn.Pending()
+}
+
+// 执行事务，replies与命令一一对应；入队时出错(如参数错误)会导致整个事务被丢弃并返回该错误，
+// WATCH的key被修改时返回ErrTxAborted
+func (t *Tx)Exec() ([]*Reply, error) {
+	n := t.conn.Pending()
+	if n == 0 {
+		return nil, nil
+	}
+	t.conn.pending = append([]*request{{"MULTI", nil}}, t.conn.pending...)
+	t.conn.Append("EXEC")
+	replies := t.conn.Flush()
+	// MULTI、n个QUEUED、EXEC
+	exec := replies[len(replies) - 1]
+	if exec.Type == ErrorReply {
+		// EXECABORT时返回导致事务被丢弃的入队错误
+		if err := firstError(replies[:len(replies) - 1]); err != nil {
+			return nil, err
+		}
+		return nil, exec.Err
+	}
+	if exec.Type == NilReply {
+		return nil, ErrTxAborted
+	}
+	if exec.Type != MultiReply || len(exec.Children) != n {
+		return nil, errors.New("reply is not formatted as an EXEC reply")
+	}
+	return exec.Children, firstError(exec.Children)
+}
+
+func (t *Tx)Discard() {
+	t.conn.Discard()
+}
+
+// 丢弃队列中的命令，不会关闭连接
+func (t *Tx)Close() {
+	t.Discard()
+}
+
+// 监视keys，之后的事务在keys被其它连接修改时不会执行
+func (g *Gedis)Watch(keys... string) (string, error) {
+	return g.Cmd("WATCH", keys).Str()
+}
+
+func (g *Gedis)Unwatch() (string, error) {
+	return g.Cmd("UNWATCH").Str()
+}
+
+func firstError(replies []*Reply) error {
+	for _, r := range replies {
+		if r.Type == ErrorReply {
+			return r.Err
+		}
+	}
+	return nil
+}
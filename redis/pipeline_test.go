@@ -0,0 +1,154 @@
+package gedis
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPipelineExec(t *testing.T) {
+	g, fc := newFakeGedis(":1\r\n", "-ERR wrong type\r\n", "$1\r\nv\r\n")
+	p := g.Pipeline()
+	if n, err := p.Incr("counter"); n != 0 || err != nil {
+		t.Fatalf("queued Incr = %d, %v, want 0, nil", n, err)
+	}
+	p.LPush("counter", "x")
+	p.Get("k")
+	if p.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", p.Len())
+	}
+	if fc.written.Len() != 0 {
+		t.Fatalf("commands written before Exec: %q", fc.written.String())
+	}
+
+	replies, err := p.Exec()
+	if err == nil || err.Error() != "ERR wrong type" {
+		t.Fatalf("Exec err = %v, want ERR wrong type", err)
+	}
+	if len(replies) != 3 {
+		t.Fatalf("len(replies) = %d, want 3", len(replies))
+	}
+	if n, _ := replies[0].Int64(); n != 1 {
+		t.Errorf("replies[0] = %d, want 1", n)
+	}
+	if s, _ := replies[2].Str(); s != "v" {
+		t.Errorf("replies[2] = %q, want v", s)
+	}
+	if p.Len() != 0 {
+		t.Errorf("Len after Exec = %d, want 0", p.Len())
+	}
+}
+
+func TestPipelineQueuedTypedMethods(t *testing.T) {
+	g, fc := newFakeGedis()
+	p := g.Pipeline()
+	if k, v, err := p.BLPop(time.Second, "q"); k != "" || v != "" || err != nil {
+		t.Errorf("BLPop = %q, %q, %v", k, v, err)
+	}
+	if x, err := p.XPending("s", "g"); x != nil || err != nil {
+		t.Errorf("XPending = %v, %v", x, err)
+	}
+	if m, err := p.LCSIdx("a", "b", 0, false); m != nil || err != nil {
+		t.Errorf("LCSIdx = %v, %v", m, err)
+	}
+	if s, err := p.ZMScore("z", "m"); s != nil || err != nil {
+		t.Errorf("ZMScore = %v, %v", s, err)
+	}
+	if tm, err := p.Time(); !tm.IsZero() || err != nil {
+		t.Errorf("Time = %v, %v", tm, err)
+	}
+	if p.Len() != 5 {
+		t.Errorf("Len = %d, want 5", p.Len())
+	}
+	if fc.written.Len() != 0 {
+		t.Errorf("commands written before Exec: %q", fc.written.String())
+	}
+}
+
+func TestTxExec(t *testing.T) {
+	g, fc := newFakeGedis("+OK\r\n", "+QUEUED\r\n", "+QUEUED\r\n", respArray(":1\r\n", "+OK\r\n"))
+	tx := g.TxPipeline()
+	tx.Incr("counter")
+	tx.Set("k", "v")
+	replies, err := tx.Exec()
+	if err != nil {
+		t.Fatalf("Exec: %v", err)
+	}
+	if len(replies) != 2 {
+		t.Fatalf("len(replies) = %d, want 2", len(replies))
+	}
+	if n, _ := replies[0].Int64(); n != 1 {
+		t.Errorf("replies[0] = %d, want 1", n)
+	}
+	if s, _ := replies[1].Str(); s != "OK" {
+		t.Errorf("replies[1] = %q, want OK", s)
+	}
+	written := fc.written.String()
+	if !strings.HasPrefix(written, "*1\r\n$5\r\nMULTI\r\n") || !strings.HasSuffix(written, "*1\r\n$4\r\nEXEC\r\n") {
+		t.Errorf("written = %q, want commands wrapped in MULTI/EXEC", written)
+	}
+}
+
+func TestTxExecEmpty(t *testing.T) {
+	g, fc := newFakeGedis()
+	replies, err := g.TxPipeline().Exec()
+	if replies != nil || err != nil {
+		t.Fatalf("Exec = %v, %v, want nil, nil", replies, err)
+	}
+	if fc.written.Len() != 0 {
+		t.Fatalf("written = %q, want nothing", fc.written.String())
+	}
+}
+
+func TestTxExecAbort(t *testing.T) {
+	g, _ := newFakeGedis(
+		"+OK\r\n",
+		"+QUEUED\r\n",
+		"-ERR wrong number of arguments for 'set' command\r\n",
+		"-EXECABORT Transaction discarded because of previous errors.\r\n",
+	)
+	tx := g.TxPipeline()
+	tx.Incr("counter")
+	tx.Cmd("SET", "k")
+	replies, err := tx.Exec()
+	if replies != nil {
+		t.Errorf("replies = %v, want nil", replies)
+	}
+	// 返回导致事务被丢弃的入队错误，而不是EXECABORT
+	if err == nil || !strings.HasPrefix(err.Error(), "ERR wrong number of arguments") {
+		t.Fatalf("Exec err = %v, want the queueing error", err)
+	}
+}
+
+func TestTxExecWatchAborted(t *testing.T) {
+	g, _ := newFakeGedis("+OK\r\n", "+QUEUED\r\n", "*-1\r\n")
+	tx := g.TxPipeline()
+	tx.Incr("counter")
+	replies, err := tx.Exec()
+	if replies != nil || err != ErrTxAborted {
+		t.Fatalf("Exec = %v, %v, want nil, ErrTxAborted", replies, err)
+	}
+}
+
+func TestPipelineDiscardedOnClose(t *testing.T) {
+	g, fc := newFakeGedis(":1\r\n")
+	pool := &recordPool{}
+	g.Pool = pool
+	p := g.Pipeline()
+	p.Set("k", "v")
+	p.Del("k")
+	g.Close()
+	if len(pool.put) != 1 {
+		t.Fatalf("put %d connections, want 1", len(pool.put))
+	}
+	// 下一个使用者的管道只发送自己的命令
+	next := g.Pipeline()
+	next.Incr("counter")
+	replies, err := next.Exec()
+	if err != nil || len(replies) != 1 {
+		t.Fatalf("Exec = %v, %v, want one reply", replies, err)
+	}
+	if w := respCommand("INCR", "counter"); fc.written.String() != w {
+		t.Errorf("written = %q, want %q", fc.written.String(), w)
+	}
+}
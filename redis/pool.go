@@ -1,7 +1,354 @@
 package gedis
 
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
 type Pool interface {
 	Get() (*Gedis, error)
 
 	Put(g *Gedis)
 }
+
+// 连接数达到MaxActive且在MaxWait内没有连接被归还时，Get返回该错误
+var ErrPoolExhausted = errors.New("gedis: connection pool exhausted")
+
+// 连接池已关闭(如哨兵模式下主从切换后旧的连接池)时，Get返回该错误
+var ErrPoolClosed = errors.New("gedis: connection pool closed")
+
+// Get在连接池耗尽时的默认等待时长
+const DefaultPoolMaxWait = 5 * time.Second
+
+// 连接池的配置
+type PoolConfig struct {
+	// 最大连接数(包括已被Get取出的)，0表示不限制
+	MaxActive int
+	// 最多保留的空闲连接数，多余的连接在归还时被关闭；0表示与MaxActive相同
+	MaxIdle   int
+	// 创建连接池时预先创建的空闲连接数
+	MinIdle   int
+	// 连接数达到MaxActive时Get的等待时长，0表示不等待直接返回ErrPoolExhausted，负数表示一直等待
+	MaxWait   time.Duration
+
+	// 取出/归还连接时执行PING检查，失败的连接被关闭，取出时会重新获取
+	TestOnBorrow     bool
+	TestOnReturn     bool
+	// 后台检查时对空闲连接执行PING
+	TestWhileIdle    bool
+	// 连接从创建起的最长使用时间，0表示不限制
+	MaxConnLifetime  time.Duration
+	// 连接的最长空闲时间，0表示不限制；用于在负载均衡、NAT等设备丢弃空闲连接之前主动关闭
+	MaxIdleTime      time.Duration
+	// 后台检查的间隔，每次检查关闭过期或PING失败的空闲连接并补充到MinIdle；0表示不进行后台检查
+	EvictionInterval time.Duration
+}
+
+// size个连接的默认配置：最多size个连接，预先创建size个
+func DefaultPoolConfig(size int) PoolConfig {
+	return PoolConfig{
+		MaxActive: size,
+		MaxIdle: size,
+		MinIdle: size,
+		MaxWait: DefaultPoolMaxWait,
+	}
+}
+
+// MaxActive及MaxIdle都不限制时，默认保留的空闲连接数
+const defaultMaxIdle = 8
+
+func (c PoolConfig) normalize() PoolConfig {
+	if c.MaxIdle <= 0 {
+		c.MaxIdle = c.MaxActive
+	}
+	if c.MaxIdle <= 0 {
+		c.MaxIdle = defaultMaxIdle
+	}
+	if c.MaxActive > 0 && c.MaxIdle > c.MaxActive {
+		c.MaxIdle = c.MaxActive
+	}
+	if c.MinIdle > c.MaxIdle {
+		c.MinIdle = c.MaxIdle
+	}
+	return c
+}
+
+// 有界连接池的通用实现，GedisPool等在其上封装具体的连接类型
+type boundedPool[T comparable] struct {
+	config  PoolConfig
+	create  func() (T, error)
+	destroy func(T)
+	ping    func(T) error
+
+	// 空闲连接
+	idle    chan idleConn[T]
+	// 每个存活的连接(包括空闲的)占用一个位置，MaxActive为0时为nil
+	active  chan struct{}
+
+	// 保护created，并使归还时的关闭检查与放入idle成为原子操作，参见pushIdle
+	mutex   sync.Mutex
+	// 连接的创建时间，用于MaxConnLifetime
+	created map[T]time.Time
+
+	closeChannel chan struct{}
+	closeOnce    sync.Once
+}
+
+type idleConn[T comparable] struct {
+	c     T
+	since time.Time
+}
+
+// 创建连接池并预先创建MinIdle个连接，任何一个创建失败都会关闭已创建的连接并返回错误；
+// EvictionInterval大于0时启动后台检查
+func newBoundedPool[T comparable](config PoolConfig, create func() (T, error), destroy func(T), ping func(T) error) (*boundedPool[T], error) {
+	config = config.normalize()
+	p := &boundedPool[T]{
+		config: config,
+		create: create,
+		destroy: destroy,
+		ping: ping,
+		idle: make(chan idleConn[T], config.MaxIdle),
+		created: make(map[T]time.Time),
+		closeChannel: make(chan struct{}),
+	}
+	if config.MaxActive > 0 {
+		p.active = make(chan struct{}, config.MaxActive)
+	}
+	if err := p.fill(); err != nil {
+		p.empty()
+		return nil, err
+	}
+	if config.EvictionInterval > 0 {
+		go p.evictor()
+	}
+	return p, nil
+}
+
+func (p *boundedPool[T]) get() (T, error) {
+	var zero T
+	for {
+		ic, ok, err := p.take()
+		if err != nil {
+			return zero, err
+		}
+		if !ok {
+			// 占用了一个新的位置
+			c, err := p.newConn()
+			if err != nil {
+				p.release()
+				return zero, err
+			}
+			return c, nil
+		}
+		if p.expired(ic) || (p.config.TestOnBorrow && p.ping(ic.c) != nil) {
+			p.discard(ic.c)
+			continue
+		}
+		return ic.c, nil
+	}
+}
+
+// 取出一个空闲连接(ok为true)，或者占用一个新建连接的位置(ok为false)；
+// 连接数达到MaxActive时按MaxWait等待其它连接被归还或关闭，连接池关闭后返回ErrPoolClosed
+func (p *boundedPool[T]) take() (idleConn[T], bool, error) {
+	select {
+	case <-p.closeChannel:
+		return idleConn[T]{}, false, ErrPoolClosed
+	default:
+	}
+	select {
+	case ic := <-p.idle:
+		return ic, true, nil
+	default:
+	}
+	if p.active == nil {
+		return idleConn[T]{}, false, nil
+	}
+	select {
+	case p.active <- struct{}{}:
+		return idleConn[T]{}, false, nil
+	default:
+	}
+	if p.config.MaxWait == 0 {
+		return idleConn[T]{}, false, ErrPoolExhausted
+	}
+	var timeout <-chan time.Time
+	if p.config.MaxWait > 0 {
+		t := time.NewTimer(p.config.MaxWait)
+		defer t.Stop()
+		timeout = t.C
+	}
+	select {
+	case ic := <-p.idle:
+		return ic, true, nil
+	case p.active <- struct{}{}:
+		return idleConn[T]{}, false, nil
+	case <-timeout:
+		return idleConn[T]{}, false, ErrPoolExhausted
+	case <-p.closeChannel:
+		return idleConn[T]{}, false, ErrPoolClosed
+	}
+}
+
+func (p *boundedPool[T]) newConn() (T, error) {
+	c, err := p.create()
+	if err != nil {
+		return c, err
+	}
+	p.mutex.Lock()
+	p.created[c] = time.Now()
+	p.mutex.Unlock()
+	return c, nil
+}
+
+func (p *boundedPool[T]) release() {
+	if p.active == nil {
+		return
+	}
+	select {
+	case <-p.active:
+	default:
+	}
+}
+
+// 空闲连接是否超过了MaxConnLifetime或MaxIdleTime
+func (p *boundedPool[T]) expired(ic idleConn[T]) bool {
+	now := time.Now()
+	if p.config.MaxIdleTime > 0 && now.Sub(ic.since) > p.config.MaxIdleTime {
+		return true
+	}
+	if p.config.MaxConnLifetime > 0 {
+		p.mutex.Lock()
+		created, ok := p.created[ic.c]
+		p.mutex.Unlock()
+		if ok && now.Sub(created) > p.config.MaxConnLifetime {
+			return true
+		}
+	}
+	return false
+}
+
+// 是否为该连接池创建的连接
+func (p *boundedPool[T]) owns(c T) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	_, ok := p.created[c]
+	return ok
+}
+
+// 归还连接，TestOnReturn检查失败或空闲连接已达MaxIdle时关闭该连接
+func (p *boundedPool[T]) put(c T) {
+	if p.config.TestOnReturn && p.ping(c) != nil {
+		p.discard(c)
+		return
+	}
+	if !p.pushIdle(idleConn[T]{c, time.Now()}) {
+		p.discard(c)
+	}
+}
+
+// 将连接放入idle，连接池已关闭或空闲连接已满时返回false，由调用方关闭该连接；
+// 与close在同一把锁下检查closeChannel，保证关闭之后不会再有连接进入idle而被遗漏
+func (p *boundedPool[T]) pushIdle(ic idleConn[T]) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	select {
+	case <-p.closeChannel:
+		return false
+	default:
+	}
+	select {
+	case p.idle <- ic:
+		return true
+	default:
+		return false
+	}
+}
+
+// 关闭连接并释放其占用的位置
+func (p *boundedPool[T]) discard(c T) {
+	p.mutex.Lock()
+	delete(p.created, c)
+	p.mutex.Unlock()
+	p.destroy(c)
+	p.release()
+}
+
+// 补充空闲连接到MinIdle，连接数达到MaxActive时停止
+func (p *boundedPool[T]) fill() error {
+	for len(p.idle) < p.config.MinIdle {
+		if p.active != nil {
+			select {
+			case p.active <- struct{}{}:
+			default:
+				return nil
+			}
+		}
+		c, err := p.newConn()
+		if err != nil {
+			p.release()
+			return err
+		}
+		if !p.pushIdle(idleConn[T]{c, time.Now()}) {
+			p.discard(c)
+			return nil
+		}
+	}
+	return nil
+}
+
+// 后台检查：定期关闭过期或PING失败的空闲连接，并补充到MinIdle
+func (p *boundedPool[T]) evictor() {
+	ticker := time.NewTicker(p.config.EvictionInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.evict()
+			// 创建失败时等待下一次检查
+			p.fill()
+		case <-p.closeChannel:
+			return
+		}
+	}
+}
+
+func (p *boundedPool[T]) evict() {
+	// 只检查当前的空闲连接，检查期间被归还的连接留到下一次
+	n := len(p.idle)
+	for i := 0; i < n; i++ {
+		var ic idleConn[T]
+		select {
+		case ic = <-p.idle:
+		default:
+			return
+		}
+		if p.expired(ic) || (p.config.TestWhileIdle && p.ping(ic.c) != nil) || !p.pushIdle(ic) {
+			p.discard(ic.c)
+		}
+	}
+}
+
+// 关闭所有空闲连接
+func (p *boundedPool[T]) empty() {
+	for {
+		select {
+		case ic := <-p.idle:
+			p.discard(ic.c)
+		default:
+			return
+		}
+	}
+}
+
+// 停止后台检查并关闭所有空闲连接，之后归还的连接会被直接关闭
+func (p *boundedPool[T]) close() {
+	p.closeOnce.Do(func() {
+		p.mutex.Lock()
+		close(p.closeChannel)
+		p.mutex.Unlock()
+	})
+	p.empty()
+}
@@ -0,0 +1,434 @@
+package gedis
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testPoolConn struct {
+	id     int
+	closed bool
+}
+
+// 记录创建及关闭的连接，pingErr不为nil时PING失败
+type testPoolFactory struct {
+	mutex   sync.Mutex
+	conns   []*testPoolConn
+	pingErr error
+}
+
+func (f *testPoolFactory) create() (*testPoolConn, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	c := &testPoolConn{id: len(f.conns)}
+	f.conns = append(f.conns, c)
+	return c, nil
+}
+
+func (f *testPoolFactory) destroy(c *testPoolConn) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	c.closed = true
+}
+
+func (f *testPoolFactory) ping(c *testPoolConn) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.pingErr
+}
+
+func (f *testPoolFactory) counts() (created, closed int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, c := range f.conns {
+		if c.closed {
+			closed++
+		}
+	}
+	return len(f.conns), closed
+}
+
+func newTestPool(t *testing.T, config PoolConfig) (*boundedPool[*testPoolConn], *testPoolFactory) {
+	t.Helper()
+	f := &testPoolFactory{}
+	p, err := newBoundedPool(config, f.create, f.destroy, f.ping)
+	if err != nil {
+		t.Fatalf("newBoundedPool: %v", err)
+	}
+	t.Cleanup(p.close)
+	return p, f
+}
+
+func TestPoolMinIdle(t *testing.T) {
+	p, f := newTestPool(t, PoolConfig{MaxActive: 4, MinIdle: 2})
+	if created, _ := f.counts(); created != 2 || len(p.idle) != 2 {
+		t.Fatalf("created %d, idle %d, want 2 and 2", created, len(p.idle))
+	}
+	c, err := p.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if created, _ := f.counts(); created != 2 || c.id != 0 {
+		t.Errorf("get created a new connection instead of reusing an idle one")
+	}
+}
+
+func TestPoolMinIdleCreateError(t *testing.T) {
+	f := &testPoolFactory{}
+	createErr := errors.New("dial failed")
+	n := 0
+	create := func() (*testPoolConn, error) {
+		if n++; n == 2 {
+			return nil, createErr
+		}
+		return f.create()
+	}
+	if _, err := newBoundedPool(PoolConfig{MaxActive: 3, MinIdle: 3}, create, f.destroy, f.ping); err != createErr {
+		t.Fatalf("newBoundedPool err = %v, want %v", err, createErr)
+	}
+	if created, closed := f.counts(); created != 1 || closed != 1 {
+		t.Errorf("created %d, closed %d, want the created connection closed", created, closed)
+	}
+}
+
+func TestPoolExhaustedWithoutWait(t *testing.T) {
+	p, _ := newTestPool(t, PoolConfig{MaxActive: 2})
+	for i := 0; i < 2; i++ {
+		if _, err := p.get(); err != nil {
+			t.Fatalf("get %d: %v", i, err)
+		}
+	}
+	if _, err := p.get(); err != ErrPoolExhausted {
+		t.Fatalf("get err = %v, want ErrPoolExhausted", err)
+	}
+}
+
+func TestPoolMaxWaitTimeout(t *testing.T) {
+	p, _ := newTestPool(t, PoolConfig{MaxActive: 1, MaxWait: 20 * time.Millisecond})
+	if _, err := p.get(); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	start := time.Now()
+	if _, err := p.get(); err != ErrPoolExhausted {
+		t.Fatalf("get err = %v, want ErrPoolExhausted", err)
+	}
+	if d := time.Since(start); d < 20*time.Millisecond {
+		t.Errorf("get returned after %v, want at least MaxWait", d)
+	}
+}
+
+func TestPoolMaxWaitReturned(t *testing.T) {
+	p, f := newTestPool(t, PoolConfig{MaxActive: 1, MaxWait: -1})
+	c, err := p.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		p.put(c)
+	}()
+	got, err := p.get()
+	if err != nil || got != c {
+		t.Fatalf("get = %v, %v, want the returned connection", got, err)
+	}
+	if created, _ := f.counts(); created != 1 {
+		t.Errorf("created %d connections, want 1", created)
+	}
+}
+
+func TestPoolDiscardFreesSlot(t *testing.T) {
+	p, f := newTestPool(t, PoolConfig{MaxActive: 1, TestOnReturn: true})
+	c, err := p.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	f.pingErr = errors.New("broken")
+	p.put(c)
+	if !c.closed {
+		t.Fatal("connection failing TestOnReturn was not closed")
+	}
+	f.pingErr = nil
+	if _, err := p.get(); err != nil {
+		t.Fatalf("get after discard: %v", err)
+	}
+}
+
+func TestPoolMaxIdle(t *testing.T) {
+	p, f := newTestPool(t, PoolConfig{MaxActive: 3, MaxIdle: 1})
+	conns := make([]*testPoolConn, 3)
+	for i := range conns {
+		c, err := p.get()
+		if err != nil {
+			t.Fatalf("get %d: %v", i, err)
+		}
+		conns[i] = c
+	}
+	for _, c := range conns {
+		p.put(c)
+	}
+	if created, closed := f.counts(); created != 3 || closed != 2 || len(p.idle) != 1 {
+		t.Fatalf("created %d, closed %d, idle %d, want 3, 2, 1", created, closed, len(p.idle))
+	}
+	// 被关闭的连接释放了位置
+	for i := 0; i < 3; i++ {
+		if _, err := p.get(); err != nil {
+			t.Fatalf("get %d after put: %v", i, err)
+		}
+	}
+}
+
+func TestPoolTestOnBorrow(t *testing.T) {
+	p, f := newTestPool(t, PoolConfig{MaxActive: 2, MinIdle: 2, TestOnBorrow: true})
+	f.pingErr = errors.New("broken")
+	// 空闲连接都PING失败，被关闭后新建连接(新建的连接不做检查)
+	c, err := p.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if created, closed := f.counts(); c.id != 2 || created != 3 || closed != 2 {
+		t.Errorf("got conn %d, created %d, closed %d, want 2, 3, 2", c.id, created, closed)
+	}
+}
+
+func TestPoolOwns(t *testing.T) {
+	p, _ := newTestPool(t, PoolConfig{MaxActive: 1})
+	c, err := p.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !p.owns(c) || p.owns(&testPoolConn{}) {
+		t.Errorf("owns reports wrong ownership")
+	}
+}
+
+func TestPoolClosed(t *testing.T) {
+	p, f := newTestPool(t, PoolConfig{MaxActive: 2, MinIdle: 1})
+	c, err := p.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	p.close()
+	if _, err := p.get(); err != ErrPoolClosed {
+		t.Fatalf("get err = %v, want ErrPoolClosed", err)
+	}
+	// 关闭后归还的连接被直接关闭
+	p.put(c)
+	if _, closed := f.counts(); !c.closed || closed != 1 {
+		t.Errorf("closed %d, want the returned connection closed", closed)
+	}
+}
+
+func TestPoolCloseWakesWaiters(t *testing.T) {
+	p, _ := newTestPool(t, PoolConfig{MaxActive: 1, MaxWait: -1})
+	if _, err := p.get(); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	errs := make(chan error)
+	go func() {
+		_, err := p.get()
+		errs <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	p.close()
+	select {
+	case err := <-errs:
+		if err != ErrPoolClosed {
+			t.Fatalf("get err = %v, want ErrPoolClosed", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("get still waiting after close")
+	}
+}
+
+func TestPoolEviction(t *testing.T) {
+	_, f := newTestPool(t, PoolConfig{
+		MaxActive:        2,
+		MinIdle:          1,
+		MaxIdleTime:      5 * time.Millisecond,
+		EvictionInterval: 10 * time.Millisecond,
+	})
+	// 过期的空闲连接被关闭，并补充新的连接到MinIdle
+	deadline := time.Now().Add(time.Second)
+	for {
+		created, closed := f.counts()
+		if closed >= 1 && created >= 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("created %d, closed %d, want the idle connection evicted and replaced", created, closed)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPoolTestWhileIdle(t *testing.T) {
+	p, f := newTestPool(t, PoolConfig{MaxActive: 1, MinIdle: 1, TestWhileIdle: true})
+	f.pingErr = errors.New("broken")
+	p.evict()
+	if created, closed := f.counts(); created != 1 || closed != 1 || len(p.idle) != 0 {
+		t.Fatalf("created %d, closed %d, idle %d, want 1, 1, 0", created, closed, len(p.idle))
+	}
+	f.pingErr = nil
+	p.evict()
+	if err := p.fill(); err != nil || len(p.idle) != 1 {
+		t.Fatalf("fill = %v, idle %d, want 1", err, len(p.idle))
+	}
+}
+
+func TestPoolMaxConnLifetime(t *testing.T) {
+	p, f := newTestPool(t, PoolConfig{MaxActive: 2, MaxConnLifetime: 10 * time.Millisecond})
+	c, err := p.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	p.put(c)
+	if got, _ := p.get(); got != c {
+		t.Fatalf("get = conn %d, want the idle connection reused", got.id)
+	}
+	p.put(c)
+	time.Sleep(20 * time.Millisecond)
+	// 超过MaxConnLifetime的空闲连接在取出时被关闭并新建
+	got, err := p.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if created, closed := f.counts(); got == c || !c.closed || created != 2 || closed != 1 {
+		t.Errorf("got conn %d, created %d, closed %d, want the expired connection replaced", got.id, created, closed)
+	}
+}
+
+func TestPoolTestOnReturn(t *testing.T) {
+	p, f := newTestPool(t, PoolConfig{MaxActive: 1, TestOnReturn: true})
+	c, err := p.get()
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	p.put(c)
+	if c.closed || len(p.idle) != 1 {
+		t.Fatalf("closed %v, idle %d, want the healthy connection kept", c.closed, len(p.idle))
+	}
+	c, _ = p.get()
+	f.mutex.Lock()
+	f.pingErr = errors.New("broken")
+	f.mutex.Unlock()
+	p.put(c)
+	if !c.closed || len(p.idle) != 0 || p.owns(c) {
+		t.Errorf("closed %v, idle %d, want the broken connection discarded", c.closed, len(p.idle))
+	}
+}
+
+// 与close并发归还的连接要么被放入idle后由close关闭，要么被直接关闭，不能遗留在已关闭的连接池中
+func TestPoolPutCloseRace(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		f := &testPoolFactory{}
+		p, err := newBoundedPool(PoolConfig{MaxActive: 8}, f.create, f.destroy, f.ping)
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns := make([]*testPoolConn, 8)
+		for j := range conns {
+			if conns[j], err = p.get(); err != nil {
+				t.Fatalf("get: %v", err)
+			}
+		}
+		var wg sync.WaitGroup
+		for _, c := range conns {
+			wg.Add(1)
+			go func(c *testPoolConn) {
+				defer wg.Done()
+				p.put(c)
+			}(c)
+		}
+		p.close()
+		wg.Wait()
+		if created, closed := f.counts(); closed != created || len(p.idle) != 0 {
+			t.Fatalf("created %d, closed %d, idle %d, want every connection closed", created, closed, len(p.idle))
+		}
+	}
+}
+
+func TestCloseGedisSkipsQuit(t *testing.T) {
+	g, fc := newFakeGedis()
+	g.Pool = &recordPool{}
+	closeGedis(g)
+	if !fc.closed || fc.written.Len() != 0 || g.Pool != nil {
+		t.Errorf("closed %v, written %q, want the socket closed without QUIT", fc.closed, fc.written.String())
+	}
+}
+
+func TestPingGedisTimeout(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    time.Duration
+	}{
+		{0, poolPingTimeout},
+		{time.Minute, poolPingTimeout},
+		{time.Second, time.Second},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis("+PONG\r\n")
+		g.conn.timeout = tt.timeout
+		start := time.Now()
+		if err := pingGedis(g); err != nil {
+			t.Fatalf("pingGedis: %v", err)
+		}
+		if d := fc.readDeadline.Sub(start); d < tt.want || d > tt.want+time.Second {
+			t.Errorf("timeout %v: read deadline %v after start, want %v", tt.timeout, d, tt.want)
+		}
+		if g.conn.timeout != tt.timeout {
+			t.Errorf("timeout %v: connection timeout changed to %v", tt.timeout, g.conn.timeout)
+		}
+	}
+}
+
+func TestShardedPoolWithConfig(t *testing.T) {
+	// 先让全局的命令元数据加载完成(失败时保留内置的命令表)，避免builder中的加载读走PING的返回值
+	g, _ := newFakeGedis("-ERR unknown command\r\n")
+	DefaultCommands.loadOnce(g)
+
+	var mutex sync.Mutex
+	var fcs []*fakeConn
+	builder := func(shards []ShardInfo) (*ShardedGedis, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		resources := make(map[ShardInfo]*Gedis)
+		for _, info := range shards {
+			g, fc := newFakeGedis("+PONG\r\n")
+			fcs = append(fcs, fc)
+			resources[info] = g
+		}
+		return &ShardedGedis{resources: resources}, nil
+	}
+	shards := []ShardInfo{*NewShardInfo("a", 1), *NewShardInfo("b", 2)}
+	p, err := NewShardedPoolWithConfig(shards, PoolConfig{MaxActive: 1, MinIdle: 1, TestOnBorrow: true}, builder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+	if len(fcs) != 2 {
+		t.Fatalf("MinIdle created %d shard connections, want 2", len(fcs))
+	}
+	sg, err := p.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	for i, fc := range fcs {
+		if w := respCommand("PING"); fc.written.String() != w {
+			t.Errorf("shard %d: written %q, want PING on borrow", i, fc.written.String())
+		}
+	}
+	if _, err := p.Get(); err != ErrPoolExhausted {
+		t.Fatalf("second Get err = %v, want ErrPoolExhausted", err)
+	}
+	// 关闭连接池后归还的连接被直接关闭，不发送QUIT
+	p.Close()
+	sg.Close()
+	for i, fc := range fcs {
+		if !fc.closed || strings.Contains(fc.written.String(), "QUIT") {
+			t.Errorf("shard %d: closed %v, written %q", i, fc.closed, fc.written.String())
+		}
+	}
+}
@@ -9,7 +9,7 @@ import (
 type PubPubSubReplyType uint8
 
 const (
-	ErrorReply PubPubSubReplyType = iota
+	PubSubErrorReply PubPubSubReplyType = iota
 	SubscribeReply
 	UnSubscribeReply
 	MessageReply
@@ -99,7 +99,7 @@ func (c *PubSubClient) parseReply(reply *Reply) *PubSubReply {
 	rtype, err := reply.Children[0].Str()
 	if err != nil {
 		sr.Err = errors.New("subscription multireply does not have string value for type")
-		sr.Type = ErrorReply
+		sr.Type = PubSubErrorReply
 		return sr
 	}
 
@@ -110,7 +110,7 @@ func (c *PubSubClient) parseReply(reply *Reply) *PubSubReply {
 		count, err := reply.Children[2].Int()
 		if err != nil {
 			sr.Err = errors.New("subscribe reply does not have int value for sub count")
-			sr.Type = ErrorReply
+			sr.Type = PubSubErrorReply
 		} else {
 			sr.SubCount = count
 		}
@@ -119,7 +119,7 @@ func (c *PubSubClient) parseReply(reply *Reply) *PubSubReply {
 		count, err := reply.Children[2].Int()
 		if err != nil {
 			sr.Err = errors.New("unsubscribe reply does not have int value for sub count")
-			sr.Type = ErrorReply
+			sr.Type = PubSubErrorReply
 		} else {
 			sr.SubCount = count
 		}
@@ -134,7 +134,7 @@ func (c *PubSubClient) parseReply(reply *Reply) *PubSubReply {
 			pattern, err := reply.Children[1].Str()
 			if err != nil {
 				sr.Err = errors.New("subscription multireply does not have string value for pattern")
-				sr.Type = ErrorReply
+				sr.Type = PubSubErrorReply
 				return sr
 			}
 			sr.Pattern = pattern
@@ -144,20 +144,20 @@ func (c *PubSubClient) parseReply(reply *Reply) *PubSubReply {
 		channel, err := reply.Children[chanI].Str()
 		if err != nil {
 			sr.Err = errors.New("subscription multireply does not have string value for channel")
-			sr.Type = ErrorReply
+			sr.Type = PubSubErrorReply
 			return sr
 		}
 		sr.Channel = channel
 		msg, err := reply.Children[msgI].Str()
 		if err != nil {
 			sr.Err = errors.New("message reply does not have string value for body")
-			sr.Type = ErrorReply
+			sr.Type = PubSubErrorReply
 		} else {
 			sr.Message = msg
 		}
 	default:
 		sr.Err = errors.New("suscription multireply has invalid type: " + rtype)
-		sr.Type = ErrorReply
+		sr.Type = PubSubErrorReply
 	}
 	return sr
 }
@@ -18,6 +18,14 @@ func (err *Error) ReadOnly() bool {
 	return strings.HasPrefix(err.Error(), "READONLY")
 }
 
+// EVALSHA/FCALL等命令执行的脚本在服务端不存在
+func (err *Error) NoScript() bool {
+	return strings.HasPrefix(err.Error(), "NOSCRIPT")
+}
+
+// 当Redis返回nil(如GET一个不存在的key)时，类型化的命令方法返回该错误
+var ErrNil = errors.New("gedis: nil reply")
+
 type ReplyType int8
 
 const (
@@ -27,6 +35,8 @@ const (
 	NilReply
 	BulkReply
 	MultiReply
+	// Pipeline/Tx中命令入队时返回的占位Reply，各转换方法返回零值及nil
+	QueuedReply
 )
 
 // Redis reply 的封装
@@ -40,6 +50,9 @@ type Reply struct {
 	int      int64
 
 	Children []*Reply
+
+	// 是否从replyPool中获取，为true时Release()会将其还回pool
+	pooled   bool
 }
 
 /////////////////////一下方法用于将Reply对象转换成不同类型的值返回/////////////////
@@ -48,16 +61,22 @@ func (r *Reply) Bytes() ([] byte, error) {
 	if r.Type == ErrorReply {
 		return nil, r.Err
 	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
 	if (r.Type == StatusReply || r.Type == BulkReply) {
 		return r.buf, nil
 	}
+	if r.Type == NilReply {
+		return nil, ErrNil
+	}
 	return nil, errors.New("string value is not available for this reply type")
 }
 
 func (r *Reply)Str() (string, error) {
 	bytes, err := r.Bytes()
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	return string(bytes), nil
 }
@@ -67,17 +86,22 @@ func (r *Reply)Int64() (int64, error) {
 		return 0, r.Err
 	}
 	if r.Type == IntegerReply {
-		str, err := r.Str()
-		if err == nil {
-			i64, err := strconv.ParseInt(str, 0, 64)
-			if err != nil {
-				return 0, errors.New("failed to parse int64 from string value")
-			}
-			return i64, nil
+		return r.int, nil
+	}
+	if r.Type == NilReply {
+		return 0, ErrNil
+	}
+	if r.Type == QueuedReply {
+		return 0, nil
+	}
+	if r.Type == BulkReply || r.Type == StatusReply {
+		i64, err := strconv.ParseInt(string(r.buf), 10, 64)
+		if err != nil {
+			return 0, errors.New("failed to parse int64 from string value")
 		}
-		return 0, errors.New("integer value is not available for this reply type")
+		return i64, nil
 	}
-	return r.int, nil
+	return 0, errors.New("integer value is not available for this reply type")
 }
 
 func (r *Reply)Int() (int, error) {
@@ -103,6 +127,12 @@ func (r *Reply)Float64() (float64, error) {
 		}
 		return f64, nil
 	}
+	if r.Type == NilReply {
+		return 0, ErrNil
+	}
+	if r.Type == QueuedReply {
+		return 0, nil
+	}
 
 	return 0, errors.New("float value is not available for this reply type")
 }
@@ -128,18 +158,22 @@ func (r *Reply)Bool() (bool, error) {
 	return false, errors.New("bool value is not available for this reply type")
 }
 
+// 将MultiReply转换成[]string，值为nil的元素转换成""
 func (r *Reply)List() ([]string, error) {
 	if r.Type == ErrorReply {
 		return nil, r.Err
 	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
 	if r.Type != MultiReply {
 		return nil, errors.New("reply type is not MultiReply")
 	}
 	list := make([]string, len(r.Children))
 	for i, v := range r.Children {
-		if r.Type == BulkReply {
+		if v.Type == BulkReply || v.Type == StatusReply {
 			list[i] = string(v.buf)
-		}else if r.Type == NilReply {
+		}else if v.Type == NilReply {
 			list[i] = ""
 		}else {
 			return nil, errors.New("children reply type is not BulkReply or NilReply")
@@ -148,18 +182,22 @@ func (r *Reply)List() ([]string, error) {
 	return list, nil
 }
 
-func (r *Reply)ListBytes() ([]byte, error) {
+// 将MultiReply转换成[][]byte，值为nil的元素保留为nil
+func (r *Reply)ListBytes() ([][]byte, error) {
 	if r.Type == ErrorReply {
 		return nil, r.Err
 	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
 	if r.Type != MultiReply {
 		return nil, errors.New("reply type is not MultiReply")
 	}
-	list := make([]byte, len(r.Children))
+	list := make([][]byte, len(r.Children))
 	for i, v := range r.Children {
-		if r.Type == BulkReply {
+		if v.Type == BulkReply || v.Type == StatusReply {
 			list[i] = v.buf
-		}else if r.Type == NilReply {
+		}else if v.Type == NilReply {
 			list[i] = nil
 		}else {
 			return nil, errors.New("children reply type is not BulkReply or NilReply")
@@ -172,6 +210,9 @@ func (r *Reply) Hash() (map[string]string, error) {
 	if r.Type == ErrorReply {
 		return nil, r.Err
 	}
+	if r.Type == QueuedReply {
+		return nil, nil
+	}
 	hash := map[string]string{}
 	if r.Type != MultiReply {
 		return nil, errors.New("reply type is not MultiReply")
@@ -179,7 +220,7 @@ func (r *Reply) Hash() (map[string]string, error) {
 	if len(r.Children) % 2 != 0 {
 		return nil, errors.New("reply has odd number of children")
 	}
-	for i := 0; i <= len(r.Children) / 2; i++ {
+	for i := 0; i < len(r.Children) / 2; i++ {
 		var value string
 		key, err := r.Children[i * 2].Str() // 取下标为偶数的child作为key
 		if err != nil {
@@ -195,7 +236,7 @@ func (r *Reply) Hash() (map[string]string, error) {
 			return nil, errors.New("value child type is not BulkReply or NilReply")
 		}
 	}
-	return hash
+	return hash, nil
 }
 
 func (r *Reply)Nil() error {
@@ -220,6 +261,8 @@ func (r *Reply)String() string {
 		return strconv.FormatInt(r.int, 10)
 	case NilReply:
 		return "<nil>"
+	case QueuedReply:
+		return "QUEUED"
 	case MultiReply:
 		s := "[ "
 		for _, e := range r.Children {
@@ -230,3 +273,65 @@ func (r *Reply)String() string {
 
 	return ""
 }
+
+// 将 key value key value ... 形式的MultiReply转换成map，value保留为原始的Reply，
+// 用于XINFO、SENTINEL MASTER等返回结构化数据的命令
+func kvReplies(r *Reply) (map[string]*Reply, error) {
+	if r.Type == ErrorReply {
+		return nil, r.Err
+	}
+	// 在Pipeline/Tx中入队时没有结果，返回空的map，调用方据此返回零值
+	if r.Type == QueuedReply {
+		return map[string]*Reply{}, nil
+	}
+	if r.Type != MultiReply {
+		return nil, errors.New("reply type is not MultiReply")
+	}
+	if len(r.Children) % 2 != 0 {
+		return nil, errors.New("reply has odd number of children")
+	}
+	m := make(map[string]*Reply, len(r.Children) / 2)
+	for i := 0; i < len(r.Children); i += 2 {
+		key, err := r.Children[i].Str()
+		if err != nil {
+			return nil, errors.New("key child is not string")
+		}
+		m[key] = r.Children[i + 1]
+	}
+	return m, nil
+}
+
+// 以下方法从kvReplies的结果中取值，字段不存在、为nil或类型不匹配时返回零值
+
+func kvStr(m map[string]*Reply, key string) string {
+	if r, ok := m[key]; ok {
+		if s, err := r.Str(); err == nil {
+			return s
+		}
+		if r.Type == IntegerReply {
+			return strconv.FormatInt(r.int, 10)
+		}
+	}
+	return ""
+}
+
+func kvInt(m map[string]*Reply, key string) int64 {
+	if r, ok := m[key]; ok {
+		if i, err := r.Int64(); err == nil {
+			return i
+		}
+	}
+	return 0
+}
+
+func kvFloat(m map[string]*Reply, key string) float64 {
+	if r, ok := m[key]; ok {
+		if r.Type == IntegerReply {
+			return float64(r.int)
+		}
+		if f, err := strconv.ParseFloat(string(r.buf), 64); err == nil && (r.Type == BulkReply || r.Type == StatusReply) {
+			return f
+		}
+	}
+	return 0
+}
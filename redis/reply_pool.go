@@ -0,0 +1,243 @@
+package gedis
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// 开启复用模式后(Connection.SetReplyPool)，Reply对象及其底层的[]byte都从sync.Pool中获取，
+// 调用方在用完Reply后需要调用Reply.Release()将其还回pool，Release之后不能再访问该Reply及其Bytes()返回的切片
+
+const (
+	// 可复用的最小/最大缓冲区，按2的幂分级，超过最大值的缓冲区直接分配，不放回pool
+	minPooledBufShift = 6  // 64B
+	maxPooledBufShift = 20 // 1MB
+)
+
+var protocolError = errors.New("gedis: invalid reply from server")
+
+var replyPool = sync.Pool{
+	New: func() interface{} {
+		return new(Reply)
+	},
+}
+
+var bufPools [maxPooledBufShift - minPooledBufShift + 1]sync.Pool
+
+// 返回能容纳n个字节的缓冲区等级，-1表示超出可复用范围
+func bufClass(n int) int {
+	for i := 0; i < len(bufPools); i++ {
+		if n <= 1 << uint(minPooledBufShift + i) {
+			return i
+		}
+	}
+	return -1
+}
+
+// 从pool中获取长度为n的缓冲区
+func getBuf(n int) []byte {
+	c := bufClass(n)
+	if c < 0 {
+		return make([]byte, n)
+	}
+	if v := bufPools[c].Get(); v != nil {
+		b := v.(*[]byte)
+		return (*b)[:n]
+	}
+	return make([]byte, n, 1 << uint(minPooledBufShift + c))
+}
+
+// 将缓冲区还回pool，只接收由getBuf分配的缓冲区
+func putBuf(b []byte) {
+	c := bufClass(cap(b))
+	if c < 0 || cap(b) != 1 << uint(minPooledBufShift + c) {
+		return
+	}
+	b = b[:0]
+	bufPools[c].Put(&b)
+}
+
+func getReply() *Reply {
+	r := replyPool.Get().(*Reply)
+	r.pooled = true
+	return r
+}
+
+// Release 将Reply及其子Reply还回pool，非复用模式下得到的Reply调用此方法无任何效果
+func (r *Reply) Release() {
+	if r == nil || !r.pooled {
+		return
+	}
+	for i, c := range r.Children {
+		c.Release()
+		r.Children[i] = nil
+	}
+	if r.buf != nil {
+		putBuf(r.buf)
+	}
+	children := r.Children[:0]
+	*r = Reply{Children: children}
+	replyPool.Put(r)
+}
+
+// 直接从bufio.Reader中解析RESP消息到复用的Reply，
+// 跳过resp.Message这一层中间对象以及raw字节的拷贝
+func readPooledReply(br *bufio.Reader) (*Reply, error) {
+	line, err := readLine(br)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line) - 2] != '\r' {
+		return nil, protocolError
+	}
+	prefix, body := line[0], line[1:len(line) - 2]
+
+	r := getReply()
+	switch prefix {
+	case '-':
+		r.Type = ErrorReply
+		msg := string(body)
+		if strings.HasPrefix(msg, "LOADING") {
+			r.Err = LoadingError
+		} else {
+			r.Err = &Error{errors.New(msg)}
+		}
+	case '+':
+		r.Type = StatusReply
+		r.buf = append(getBuf(len(body))[:0], body...)
+	case ':':
+		i, err := parseLineInt(body)
+		if err != nil {
+			r.Release()
+			return nil, err
+		}
+		r.Type = IntegerReply
+		r.int = i
+	case '$':
+		n, err := parseLineInt(body)
+		if err != nil {
+			r.Release()
+			return nil, err
+		}
+		if n < 0 {
+			r.Type = NilReply
+			return r, nil
+		}
+		r.Type = BulkReply
+		r.buf = getBuf(int(n))
+		if _, err = io.ReadFull(br, r.buf); err == nil {
+			_, err = br.Discard(2)
+		}
+		if err != nil {
+			r.Release()
+			return nil, err
+		}
+	case '*':
+		n, err := parseLineInt(body)
+		if err != nil {
+			r.Release()
+			return nil, err
+		}
+		if n < 0 {
+			r.Type = NilReply
+			return r, nil
+		}
+		r.Type = MultiReply
+		if cap(r.Children) < int(n) {
+			r.Children = make([]*Reply, 0, n)
+		}
+		for i := int64(0); i < n; i++ {
+			c, err := readPooledReply(br)
+			if err != nil {
+				r.Release()
+				return nil, err
+			}
+			r.Children = append(r.Children, c)
+		}
+	default:
+		r.Release()
+		return nil, protocolError
+	}
+	return r, nil
+}
+
+// 读取一行(包括\r\n)，通常直接返回bufio.Reader内部缓冲区的切片，在下一次读取之前有效；
+// 超过缓冲区大小的行(如很长的错误信息)拼接到新分配的切片中
+func readLine(br *bufio.Reader) ([]byte, error) {
+	line, err := br.ReadSlice('\n')
+	if err != bufio.ErrBufferFull {
+		return line, err
+	}
+	full := append([]byte(nil), line...)
+	for err == bufio.ErrBufferFull {
+		line, err = br.ReadSlice('\n')
+		full = append(full, line...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return full, nil
+}
+
+// 以下方法供返回集合的命令方法使用：转换出的值不引用Reply的缓冲区，转换后立即将Reply还回pool，
+// 非复用模式下与List/ListBytes/Hash相同
+
+func (r *Reply) releaseList() ([]string, error) {
+	defer r.Release()
+	return r.List()
+}
+
+func (r *Reply) releaseHash() (map[string]string, error) {
+	defer r.Release()
+	return r.Hash()
+}
+
+// 复用模式下ListBytes返回的切片指向pool中的缓冲区，需要拷贝到一块新分配的缓冲区中
+func (r *Reply) releaseListBytes() ([][]byte, error) {
+	defer r.Release()
+	list, err := r.ListBytes()
+	if err != nil || !r.pooled {
+		return list, err
+	}
+	n := 0
+	for _, b := range list {
+		n += len(b)
+	}
+	buf := make([]byte, 0, n)
+	for i, b := range list {
+		if b != nil {
+			buf = append(buf, b...)
+			list[i] = buf[len(buf) - len(b):len(buf):len(buf)]
+		}
+	}
+	return list, nil
+}
+
+// 解析RESP头部中的整数，避免strconv.ParseInt(string(b))带来的分配
+func parseLineInt(b []byte) (int64, error) {
+	if len(b) == 0 {
+		return 0, protocolError
+	}
+	neg := false
+	if b[0] == '-' {
+		neg = true
+		b = b[1:]
+		if len(b) == 0 {
+			return 0, protocolError
+		}
+	}
+	var n int64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, protocolError
+		}
+		n = n * 10 + int64(c - '0')
+	}
+	if neg {
+		n = -n
+	}
+	return n, nil
+}
@@ -0,0 +1,214 @@
+package gedis
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func readPooled(t *testing.T, s string) *Reply {
+	t.Helper()
+	r, err := readPooledReply(bufio.NewReaderSize(strings.NewReader(s), 16))
+	if err != nil {
+		t.Fatalf("readPooledReply(%q): %v", s, err)
+	}
+	return r
+}
+
+func TestReadPooledReply(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		typ  ReplyType
+	}{
+		{"+OK\r\n", "OK", StatusReply},
+		{":-42\r\n", "-42", IntegerReply},
+		{"$5\r\nhello\r\n", "hello", BulkReply},
+		{"$0\r\n\r\n", "", BulkReply},
+		{"$-1\r\n", "<nil>", NilReply},
+		{"*-1\r\n", "<nil>", NilReply},
+		{"-ERR wrong\r\n", "ERR wrong", ErrorReply},
+		{"*2\r\n$1\r\na\r\n*1\r\n:1\r\n", "[ a [ 1 ] ]", MultiReply},
+		{"*0\r\n", "[ ]", MultiReply},
+		// 超过bufio.Reader缓冲区大小的行
+		{"+" + strings.Repeat("s", 100) + "\r\n", strings.Repeat("s", 100), StatusReply},
+		{"-ERR " + strings.Repeat("e", 100) + "\r\n", "ERR " + strings.Repeat("e", 100), ErrorReply},
+	}
+	for _, tt := range tests {
+		r := readPooled(t, tt.in)
+		if r.Type != tt.typ || r.String() != tt.want {
+			t.Errorf("readPooledReply(%q) = %v %q, want %v %q", tt.in, r.Type, r.String(), tt.typ, tt.want)
+		}
+		if !r.pooled {
+			t.Errorf("readPooledReply(%q) is not pooled", tt.in)
+		}
+		r.Release()
+	}
+}
+
+func TestReadPooledReplyLoading(t *testing.T) {
+	r := readPooled(t, "-LOADING Redis is loading the dataset in memory\r\n")
+	if r.Err != LoadingError {
+		t.Fatalf("Err = %v, want LoadingError", r.Err)
+	}
+}
+
+func TestReadPooledReplyInvalid(t *testing.T) {
+	for _, in := range []string{"", "+OK\n", "?x\r\n", ":12a\r\n", "$-\r\n", "$5\r\nab", "*2\r\n:1\r\n"} {
+		r, err := readPooledReply(bufio.NewReader(strings.NewReader(in)))
+		if err == nil {
+			t.Errorf("readPooledReply(%q) = %v, want error", in, r)
+		}
+	}
+}
+
+func TestReplyRelease(t *testing.T) {
+	r := readPooled(t, "*2\r\n$3\r\nfoo\r\n$3\r\nbar\r\n")
+	children := r.Children
+	r.Release()
+	if r.Type != 0 || r.buf != nil || len(r.Children) != 0 || r.pooled {
+		t.Fatalf("released reply is not reset: %+v", r)
+	}
+	for _, c := range children {
+		if c != nil {
+			t.Fatalf("released children are still referenced")
+		}
+	}
+
+	// 非复用模式下的Reply不受影响
+	plain := &Reply{Type: BulkReply, buf: []byte("foo")}
+	plain.Release()
+	if s, _ := plain.Str(); s != "foo" {
+		t.Fatalf("Release changed a non-pooled reply: %q", s)
+	}
+	var nilReply *Reply
+	nilReply.Release()
+}
+
+func TestPooledTypedHelpersCopy(t *testing.T) {
+	g, _ := newFakeGedis(
+		respArray(respBulk("v1"), "$-1\r\n", respBulk("v3")),
+		respArray(respBulk("a"), respBulk("b")),
+		// 下一次读取会复用上面Release的缓冲区
+		respArray(respBulk("xx"), respBulk("yy"), respBulk("zz")),
+	)
+	g.SetReplyPool(true)
+	values, err := g.MGet("k1", "k2", "k3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, err := g.LRange("l", 0, -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.MGet("k4", "k5", "k6"); err != nil {
+		t.Fatal(err)
+	}
+	if string(values[0]) != "v1" || values[1] != nil || string(values[2]) != "v3" {
+		t.Fatalf("MGet = %q", values)
+	}
+	if len(list) != 2 || list[0] != "a" || list[1] != "b" {
+		t.Fatalf("LRange = %q", list)
+	}
+}
+
+func TestParseLineInt(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"0", 0, true},
+		{"123", 123, true},
+		{"-1", -1, true},
+		{"", 0, false},
+		{"-", 0, false},
+		{"1x", 0, false},
+	}
+	for _, tt := range tests {
+		n, err := parseLineInt([]byte(tt.in))
+		if (err == nil) != tt.ok || n != tt.want {
+			t.Errorf("parseLineInt(%q) = %d, %v", tt.in, n, err)
+		}
+	}
+}
+
+func benchReply(n int, size int) string {
+	items := make([]string, n)
+	for i := range items {
+		items[i] = respBulk(strings.Repeat(strconv.Itoa(i%10), size))
+	}
+	return respArray(items...)
+}
+
+func newBenchGedis(reply string, pooled bool) *Gedis {
+	fc := &fakeConn{script: []byte(reply), repeat: true}
+	g := &Gedis{conn: newFakeConnection(fc)}
+	g.SetReplyPool(pooled)
+	return g
+}
+
+func benchmarkMGet(b *testing.B, pooled bool) {
+	keys := make([]string, 100)
+	for i := range keys {
+		keys[i] = "key:" + strconv.Itoa(i)
+	}
+	g := newBenchGedis(benchReply(len(keys), 64), pooled)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.MGet(keys...); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMGet(b *testing.B) {
+	benchmarkMGet(b, false)
+}
+
+func BenchmarkMGetPooled(b *testing.B) {
+	benchmarkMGet(b, true)
+}
+
+func benchmarkLRange(b *testing.B, pooled bool) {
+	g := newBenchGedis(benchReply(100, 64), pooled)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := g.LRange("list", 0, 99); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLRange(b *testing.B) {
+	benchmarkLRange(b, false)
+}
+
+func BenchmarkLRangePooled(b *testing.B) {
+	benchmarkLRange(b, true)
+}
+
+// 只读取而不转换，体现Reply复用本身减少的分配
+func benchmarkReadReply(b *testing.B, pooled bool) {
+	g := newBenchGedis(benchReply(100, 64), pooled)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r := g.ReadReply()
+		if r.Type != MultiReply {
+			b.Fatal(r.Err)
+		}
+		r.Release()
+	}
+}
+
+func BenchmarkReadReply(b *testing.B) {
+	benchmarkReadReply(b, false)
+}
+
+func BenchmarkReadReplyPooled(b *testing.B) {
+	benchmarkReadReply(b, true)
+}
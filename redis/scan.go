@@ -0,0 +1,228 @@
+package gedis
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// SCAN/HSCAN/SSCAN/ZSCAN 的游标迭代器，隐藏游标的维护:
+//
+//	it := gedis.Scan(ctx, &ScanOptions{Match: "user:*", Count: 100})
+//	for it.Next() {
+//		key := it.Val()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+//
+// 也可以使用Go 1.23的range-over-func:
+//
+//	for key := range it.All() {
+//	}
+//
+// 注意SCAN类命令只保证遍历开始前就存在且一直存在的元素一定会被返回，同一个元素可能被返回多次
+type Iterator[T any] struct {
+	gedis   *Gedis
+	ctx     context.Context
+
+	cmd     string
+	// HSCAN/SSCAN/ZSCAN的key，SCAN时为空
+	key     string
+	args    []interface{}
+	parse   func([]*Reply) ([]T, error)
+
+	cursor  string
+	// 服务端返回游标"0"后置为true，当前页消费完即结束
+	done    bool
+	page    []T
+	pos     int
+	val     T
+	err     error
+}
+
+// SCAN类命令的可选参数，Type只对SCAN有效
+type ScanOptions struct {
+	Match string
+	Count int64
+	Type  string
+}
+
+func (o *ScanOptions) args(withType bool) []interface{} {
+	args := make([]interface{}, 0, 6)
+	if o == nil {
+		return args
+	}
+	if o.Match != "" {
+		args = append(args, "MATCH", o.Match)
+	}
+	if o.Count > 0 {
+		args = append(args, "COUNT", o.Count)
+	}
+	if withType && o.Type != "" {
+		args = append(args, "TYPE", o.Type)
+	}
+	return args
+}
+
+func newIterator[T any](ctx context.Context, g *Gedis, cmd, key string, args []interface{}, parse func([]*Reply) ([]T, error)) *Iterator[T] {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &Iterator[T]{
+		gedis: g,
+		ctx: ctx,
+		cmd: cmd,
+		key: key,
+		args: args,
+		parse: parse,
+		cursor: "0",
+	}
+}
+
+// 遍历当前db中的key
+func (g *Gedis)Scan(ctx context.Context, opt *ScanOptions) *Iterator[string] {
+	return newIterator(ctx, g, "SCAN", "", opt.args(true), parseScanStrings)
+}
+
+// 遍历hash的field及其值
+func (g *Gedis)HScan(ctx context.Context, key string, opt *ScanOptions) *Iterator[HashField] {
+	return newIterator(ctx, g, "HSCAN", key, opt.args(false), parseScanHashFields)
+}
+
+// HSCAN ... NOVALUES，只遍历hash的field(Redis 7.4+)
+func (g *Gedis)HScanNoValues(ctx context.Context, key string, opt *ScanOptions) *Iterator[string] {
+	return newIterator(ctx, g, "HSCAN", key, append(opt.args(false), "NOVALUES"), parseScanStrings)
+}
+
+// 遍历集合的成员
+func (g *Gedis)SScan(ctx context.Context, key string, opt *ScanOptions) *Iterator[string] {
+	return newIterator(ctx, g, "SSCAN", key, opt.args(false), parseScanStrings)
+}
+
+// 遍历有序集合的成员及其分值
+func (g *Gedis)ZScan(ctx context.Context, key string, opt *ScanOptions) *Iterator[Z] {
+	return newIterator(ctx, g, "ZSCAN", key, opt.args(false), parseScanZ)
+}
+
+// 移动到下一个元素，没有更多元素、出错或ctx被取消时返回false
+func (it *Iterator[T]) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	for it.pos >= len(it.page) {
+		if it.done {
+			return false
+		}
+		if err := it.fetch(); err != nil {
+			it.err = err
+			return false
+		}
+	}
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return false
+	}
+	it.val = it.page[it.pos]
+	it.pos++
+	return true
+}
+
+// 返回当前元素，只在Next返回true之后有效
+func (it *Iterator[T]) Val() T {
+	return it.val
+}
+
+// 返回迭代过程中的错误，包括ctx被取消时的ctx.Err()
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// 返回iter.Seq形式的迭代器，循环结束后需要通过Err检查是否出错
+func (it *Iterator[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for it.Next() {
+			if !yield(it.Val()) {
+				return
+			}
+		}
+	}
+}
+
+// 获取下一页，服务端返回的游标为"0"时表示遍历结束，
+// 当前页可能为空但游标不为"0"，此时需要继续获取
+func (it *Iterator[T]) fetch() error {
+	if err := it.ctx.Err(); err != nil {
+		return err
+	}
+	var r *Reply
+	if it.key == "" {
+		r = it.gedis.Cmd(it.cmd, it.cursor, it.args)
+	} else {
+		r = it.gedis.Cmd(it.cmd, it.key, it.cursor, it.args)
+	}
+	if r.Type == ErrorReply {
+		return r.Err
+	}
+	if r.Type != MultiReply || len(r.Children) != 2 {
+		return errors.New("reply is not formatted as a scan reply")
+	}
+	cursor, err := r.Children[0].Str()
+	if err != nil {
+		return err
+	}
+	page, err := it.parse(r.Children[1].Children)
+	if err != nil {
+		return err
+	}
+	it.cursor = cursor
+	it.done = cursor == "0"
+	it.page = page
+	it.pos = 0
+	return nil
+}
+
+func parseScanStrings(rs []*Reply) ([]string, error) {
+	list := make([]string, len(rs))
+	for i, r := range rs {
+		s, err := r.Str()
+		if err != nil {
+			return nil, err
+		}
+		list[i] = s
+	}
+	return list, nil
+}
+
+func parseScanHashFields(rs []*Reply) ([]HashField, error) {
+	if len(rs) % 2 != 0 {
+		return nil, errors.New("reply has odd number of children")
+	}
+	fields := make([]HashField, len(rs) / 2)
+	for i := range fields {
+		field, err := rs[i * 2].Str()
+		if err != nil {
+			return nil, err
+		}
+		value, err := rs[i * 2 + 1].Str()
+		if err != nil {
+			return nil, err
+		}
+		fields[i] = HashField{Field: field, Value: value}
+	}
+	return fields, nil
+}
+
+func parseScanZ(rs []*Reply) ([]Z, error) {
+	if len(rs) % 2 != 0 {
+		return nil, errors.New("reply has odd number of children")
+	}
+	zs := make([]Z, len(rs) / 2)
+	for i := range zs {
+		z, err := parseZ(rs[i * 2 : i * 2 + 2])
+		if err != nil {
+			return nil, err
+		}
+		zs[i] = z
+	}
+	return zs, nil
+}
@@ -0,0 +1,112 @@
+package gedis
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func scanPage(cursor string, items ...string) string {
+	bulks := make([]string, len(items))
+	for i, item := range items {
+		bulks[i] = respBulk(item)
+	}
+	return respArray(respBulk(cursor), respArray(bulks...))
+}
+
+func TestIterator(t *testing.T) {
+	tests := []struct {
+		name  string
+		pages []string
+		want  []string
+		calls int
+	}{
+		{"single page", []string{scanPage("0", "a", "b")}, []string{"a", "b"}, 1},
+		{"empty", []string{scanPage("0")}, nil, 1},
+		{"empty page in the middle", []string{scanPage("7", "a"), scanPage("3"), scanPage("0", "b")}, []string{"a", "b"}, 3},
+		{"ends on empty page", []string{scanPage("5", "a", "b"), scanPage("0")}, []string{"a", "b"}, 2},
+		{"empty pages only", []string{scanPage("5"), scanPage("9"), scanPage("0")}, nil, 3},
+	}
+	for _, tt := range tests {
+		g, fc := newFakeGedis(tt.pages...)
+		it := g.Scan(context.Background(), &ScanOptions{Match: "*", Count: 10})
+		var got []string
+		for key := range it.All() {
+			got = append(got, key)
+		}
+		if err := it.Err(); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		if calls := strings.Count(fc.written.String(), "SCAN"); calls != tt.calls {
+			t.Errorf("%s: %d SCAN calls, want %d", tt.name, calls, tt.calls)
+		}
+		if it.Next() {
+			t.Errorf("%s: Next after end returned true", tt.name)
+		}
+	}
+}
+
+func TestIteratorCursor(t *testing.T) {
+	g, fc := newFakeGedis(scanPage("17", "a"), scanPage("0", "b"))
+	it := g.SScan(context.Background(), "set", nil)
+	for it.Next() {
+	}
+	want := "*3\r\n$5\r\nSSCAN\r\n$3\r\nset\r\n$1\r\n0\r\n" +
+		"*3\r\n$5\r\nSSCAN\r\n$3\r\nset\r\n$2\r\n17\r\n"
+	if fc.written.String() != want {
+		t.Fatalf("written %q, want %q", fc.written.String(), want)
+	}
+}
+
+func TestIteratorError(t *testing.T) {
+	g, _ := newFakeGedis(scanPage("3", "a"), "-ERR boom\r\n")
+	it := g.Scan(context.Background(), nil)
+	n := 0
+	for it.Next() {
+		n++
+	}
+	if n != 1 || it.Err() == nil || it.Err().Error() != "ERR boom" {
+		t.Fatalf("n = %d, err = %v", n, it.Err())
+	}
+}
+
+func TestIteratorContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	g, _ := newFakeGedis(scanPage("3", "a", "b"), scanPage("0", "c"))
+	it := g.Scan(ctx, nil)
+	if !it.Next() || it.Val() != "a" {
+		t.Fatal("first Next failed")
+	}
+	cancel()
+	if it.Next() {
+		t.Fatal("Next after cancel returned true")
+	}
+	if it.Err() != context.Canceled {
+		t.Fatalf("err = %v", it.Err())
+	}
+}
+
+func TestScanHashFieldsAndZ(t *testing.T) {
+	g, _ := newFakeGedis(
+		scanPage("0", "f1", "v1", "f2", "v2"),
+		scanPage("0", "m1", "1.5", "m2", "-2"),
+	)
+	var fields []HashField
+	for f := range g.HScan(context.Background(), "h", nil).All() {
+		fields = append(fields, f)
+	}
+	if len(fields) != 2 || fields[1] != (HashField{Field: "f2", Value: "v2"}) {
+		t.Errorf("HScan = %+v", fields)
+	}
+	var zs []Z
+	for z := range g.ZScan(context.Background(), "z", nil).All() {
+		zs = append(zs, z)
+	}
+	if len(zs) != 2 || zs[0].Score != 1.5 || zs[1].Score != -2 {
+		t.Errorf("ZScan = %+v", zs)
+	}
+}
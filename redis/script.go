@@ -0,0 +1,173 @@
+package gedis
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+)
+
+// 一个Lua脚本及其SHA1，执行时优先使用EVALSHA，服务端没有缓存该脚本(NOSCRIPT)时自动使用EVAL执行，
+// EVAL执行后脚本即被服务端缓存，后续的EVALSHA可以直接命中
+type Script struct {
+	src  string
+	hash string
+}
+
+func NewScript(src string) *Script {
+	h := sha1.Sum([]byte(src))
+	return &Script{
+		src: src,
+		hash: hex.EncodeToString(h[:]),
+	}
+}
+
+func (s *Script)Source() string {
+	return s.src
+}
+
+// 脚本的SHA1，即EVALSHA使用的值
+func (s *Script)Hash() string {
+	return s.hash
+}
+
+// 通过EVALSHA执行脚本，NOSCRIPT时使用EVAL重试
+func (s *Script)Run(g *Gedis, keys []string, args... interface{}) *Reply {
+	r := g.EvalSha(s.hash, keys, args...)
+	if isNoScript(r) {
+		return g.Eval(s.src, keys, args...)
+	}
+	return r
+}
+
+// 通过EVALSHA_RO执行只读脚本，可以在只读副本上执行，NOSCRIPT时使用EVAL_RO重试
+func (s *Script)RunRO(g *Gedis, keys []string, args... interface{}) *Reply {
+	r := g.EvalShaRO(s.hash, keys, args...)
+	if isNoScript(r) {
+		return g.EvalRO(s.src, keys, args...)
+	}
+	return r
+}
+
+// 将脚本加载到服务端缓存
+func (s *Script)Load(g *Gedis) error {
+	_, err := g.ScriptLoad(s.src)
+	return err
+}
+
+// 服务端是否已缓存该脚本
+func (s *Script)Exists(g *Gedis) (bool, error) {
+	exists, err := g.ScriptExists(s.hash)
+	if err != nil {
+		return false, err
+	}
+	return len(exists) == 1 && exists[0], nil
+}
+
+func isNoScript(r *Reply) bool {
+	if r.Type != ErrorReply {
+		return false
+	}
+	e, ok := r.Err.(*Error)
+	return ok && e.NoScript()
+}
+
+func (g *Gedis)Eval(script string, keys []string, args... interface{}) *Reply {
+	return g.Cmd("EVAL", script, len(keys), keys, args)
+}
+
+func (g *Gedis)EvalSha(sha1 string, keys []string, args... interface{}) *Reply {
+	return g.Cmd("EVALSHA", sha1, len(keys), keys, args)
+}
+
+// 只读版本的EVAL(Redis 7.0+)，脚本中不能执行写命令
+func (g *Gedis)EvalRO(script string, keys []string, args... interface{}) *Reply {
+	return g.Cmd("EVAL_RO", script, len(keys), keys, args)
+}
+
+func (g *Gedis)EvalShaRO(sha1 string, keys []string, args... interface{}) *Reply {
+	return g.Cmd("EVALSHA_RO", sha1, len(keys), keys, args)
+}
+
+// 加载脚本到服务端缓存，返回脚本的SHA1
+func (g *Gedis)ScriptLoad(script string) (string, error) {
+	return g.Cmd("SCRIPT", "LOAD", script).Str()
+}
+
+// 返回值与hashes一一对应，表示服务端是否已缓存对应的脚本
+func (g *Gedis)ScriptExists(hashes... string) ([]bool, error) {
+	list, err := intList(g.Cmd("SCRIPT", "EXISTS", hashes))
+	if err != nil {
+		return nil, err
+	}
+	exists := make([]bool, len(list))
+	for i, n := range list {
+		exists[i] = n == 1
+	}
+	return exists, nil
+}
+
+// 清空服务端的脚本缓存，async为true时异步清空
+func (g *Gedis)ScriptFlush(async bool) (string, error) {
+	if async {
+		return g.Cmd("SCRIPT", "FLUSH", "ASYNC").Str()
+	}
+	return g.Cmd("SCRIPT", "FLUSH", "SYNC").Str()
+}
+
+// 终止正在执行且未执行过写操作的脚本
+func (g *Gedis)ScriptKill() (string, error) {
+	return g.Cmd("SCRIPT", "KILL").Str()
+}
+
+// 将scripts加载到g所连接的服务端
+func LoadScripts(g *Gedis, scripts... *Script) error {
+	for _, s := range scripts {
+		if err := s.Load(g); err != nil {
+			return errors.New("load script " + s.hash + " error:" + err.Error())
+		}
+	}
+	return nil
+}
+
+// 包装PoolBuilder，每创建一个新连接都预先加载scripts，
+// 可用于NewGedisPoolWithCustom、NewSentinelPoolWithCustom等需要PoolBuilder的地方:
+//
+//	pool, err := NewGedisPoolWithCustom(host, port, size, PreloadScripts(NewGedis, limiter, inventory))
+func PreloadScripts(builder PoolBuilder, scripts... *Script) PoolBuilder {
+	if builder == nil {
+		builder = NewGedis
+	}
+	return func(host string, port int) (*Gedis, error) {
+		g, err := builder(host, port)
+		if err != nil {
+			return nil, err
+		}
+		if err = LoadScripts(g, scripts...); err != nil {
+			g.Close()
+			return nil, err
+		}
+		return g, nil
+	}
+}
+
+// 包装ShardedPoolBuilder，每创建一个新的ShardedGedis都在所有分片上预先加载scripts:
+//
+//	pool, err := NewShardedPoolWithCustom(shards, size, PreloadShardedScripts(NewShardedGedis, limiter))
+func PreloadShardedScripts(builder ShardedPoolBuilder, scripts... *Script) ShardedPoolBuilder {
+	if builder == nil {
+		builder = NewShardedGedis
+	}
+	return func(shards []ShardInfo) (*ShardedGedis, error) {
+		sg, err := builder(shards)
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range sg.AllShards() {
+			if err = LoadScripts(g, scripts...); err != nil {
+				sg.Close()
+				return nil, err
+			}
+		}
+		return sg, nil
+	}
+}
@@ -0,0 +1,78 @@
+package gedis
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestScriptRunFallback(t *testing.T) {
+	s := NewScript("return 1")
+	g, fc := newFakeGedis("-NOSCRIPT No matching script.\r\n", ":1\r\n")
+	n, err := s.Run(g, nil).Int64()
+	if err != nil || n != 1 {
+		t.Fatalf("Run = %d, %v", n, err)
+	}
+	written := fc.written.String()
+	if !strings.Contains(written, "EVALSHA") || !strings.Contains(written, "$4\r\nEVAL\r\n") {
+		t.Fatalf("written %q, want EVALSHA then EVAL", written)
+	}
+}
+
+func TestPreloadScripts(t *testing.T) {
+	s := NewScript("return 1")
+	var fcs []*fakeConn
+	builder := func(host string, port int) (*Gedis, error) {
+		g, fc := newFakeGedis(respBulk(s.Hash()))
+		fcs = append(fcs, fc)
+		return g, nil
+	}
+	g, err := PreloadScripts(builder, s)("localhost", 6379)
+	if err != nil || g == nil {
+		t.Fatalf("PreloadScripts: %v", err)
+	}
+	if !strings.Contains(fcs[0].written.String(), "LOAD") {
+		t.Fatalf("script not loaded: %q", fcs[0].written.String())
+	}
+
+	failing := func(host string, port int) (*Gedis, error) {
+		g, _ := newFakeGedis("-ERR denied\r\n", "+OK\r\n")
+		return g, nil
+	}
+	if _, err := PreloadScripts(failing, s)("localhost", 6379); err == nil {
+		t.Fatal("PreloadScripts should fail when SCRIPT LOAD fails")
+	}
+
+	broken := func(host string, port int) (*Gedis, error) {
+		return nil, errors.New("dial error")
+	}
+	if _, err := PreloadScripts(broken, s)("localhost", 6379); err == nil || err.Error() != "dial error" {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestPreloadShardedScripts(t *testing.T) {
+	s := NewScript("return 1")
+	var fcs []*fakeConn
+	builder := func(shards []ShardInfo) (*ShardedGedis, error) {
+		resources := make(map[ShardInfo]*Gedis)
+		for _, info := range shards {
+			g, fc := newFakeGedis(respBulk(s.Hash()))
+			fcs = append(fcs, fc)
+			resources[info] = g
+		}
+		return &ShardedGedis{resources: resources}, nil
+	}
+	shards := []ShardInfo{*NewShardInfo("a", 1), *NewShardInfo("b", 2)}
+	if _, err := PreloadShardedScripts(builder, s)(shards); err != nil {
+		t.Fatal(err)
+	}
+	if len(fcs) != 2 {
+		t.Fatalf("%d shards built", len(fcs))
+	}
+	for i, fc := range fcs {
+		if !strings.Contains(fc.written.String(), "LOAD") {
+			t.Errorf("script not loaded on shard %d: %q", i, fc.written.String())
+		}
+	}
+}
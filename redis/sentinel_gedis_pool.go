@@ -2,6 +2,7 @@ package gedis
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -14,9 +15,9 @@ const (
 )
 
 type SentinelGedisPool struct {
-	size              int
-	// 存放主节点的连接对象
-	pool              chan *Gedis
+	config            PoolConfig
+	// 存放主节点的连接对象，主从切换后替换为新master的连接池
+	pool              *boundedPool[*Gedis]
 	// 主节点
 	currentHostMaster HostAndPort
 	// 哨兵监听
@@ -27,54 +28,85 @@ type SentinelGedisPool struct {
 	mutex             sync.Mutex
 }
 
+// 最多size个连接，参见DefaultPoolConfig
 func NewSentinelPool(masterName string, sentinels []HostAndPort, size int) (*SentinelGedisPool, error) {
-	master := getMasterBySentinels(masterName, sentinels)
-	if master == nil {
+	return NewSentinelPoolWithCustom(masterName, sentinels, size, NewGedis)
+}
+
+func NewSentinelPoolWithCustom(masterName string, sentinels []HostAndPort, size int, builder PoolBuilder) (*SentinelGedisPool, error) {
+	return NewSentinelPoolWithConfig(masterName, sentinels, DefaultPoolConfig(size), builder)
+}
+
+// builder用于创建到master的连接，为nil时使用NewGedis；到哨兵的连接始终使用NewGedis创建
+func NewSentinelPoolWithConfig(masterName string, sentinels []HostAndPort, config PoolConfig, builder PoolBuilder) (*SentinelGedisPool, error) {
+	if builder == nil {
+		builder = NewGedis
+	}
+	master := getMasterBySentinels(masterName, sentinels, builder)
+	if master == (HostAndPort{}) {
 		return nil, errors.New("Can connect to sentinel, but " + masterName + " seems to be not monitored...")
 	}
 
 	sgp := &SentinelGedisPool{
-		size : size,
+		config: config,
 		currentHostMaster : master,
-		builder: NewGedis(master.GetHost(), master.GetPort()),
-		pool:make(chan *Gedis, size),
+		builder: builder,
 		sentinelListeners: make([] *SentinelListener, 0, len(sentinels)),
 	}
 	sentinelErr := sgp.initSentinels(masterName, sentinels)
 	if sentinelErr != nil {
 		return nil, sentinelErr
 	}
-	err := sgp.initPool(master)
+	pool, err := sgp.newMasterPool(master)
 	if err != nil {
 		return nil, err
 	}
+	sgp.pool = pool
 	return sgp, nil
 }
 
+// 主从切换时旧的连接池被关闭，此时在新的连接池上重新获取
 func (sgp *SentinelGedisPool)Get() (*Gedis, error) {
-	select {
-	case g := <-sgp.pool:
+	sgp.mutex.Lock()
+	pool := sgp.pool
+	sgp.mutex.Unlock()
+	for {
+		g, err := pool.get()
+		if err == ErrPoolClosed {
+			sgp.mutex.Lock()
+			current := sgp.pool
+			sgp.mutex.Unlock()
+			if current != pool {
+				pool = current
+				continue
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
 		g.Pool = sgp
 		return g, nil
-	default:
-		return nil, errors.New("cannt get gedis in pool")
 	}
 }
 
+// 主从切换前取出的连接在归还时被直接关闭，已关闭的连接被丢弃并释放其占用的位置
 func (sgp *SentinelGedisPool)Put(g *Gedis) {
-	select {
-	case sgp.pool <- g:
-	default:
-		g.Close()
+	sgp.mutex.Lock()
+	pool := sgp.pool
+	sgp.mutex.Unlock()
+	if !pool.owns(g) {
+		closeGedis(g)
+	} else if g.conn.isClosed() {
+		pool.discard(g)
+	} else {
+		pool.put(g)
 	}
 }
 
 func (sgp *SentinelGedisPool)Close() {
-	poolSize := len(sgp.pool)
-	for i := 0; i < poolSize; i++ {
-		gedis := <-sgp.pool
-		gedis.Close()
-	}
+	sgp.mutex.Lock()
+	sgp.pool.close()
+	sgp.mutex.Unlock()
 
 	// 停止监听
 	for _, listener := range sgp.sentinelListeners {
@@ -83,39 +115,41 @@ func (sgp *SentinelGedisPool)Close() {
 }
 
 // 根据sentinel获取master
-func getMasterBySentinels(masterName string, sentinels []HostAndPort) HostAndPort {
-	var master HostAndPort
+// 哨兵的视图可能落后于实际的切换，通过ROLE(使用builder创建连接，以便带上AUTH等)确认该节点确实是master；
+// 所有哨兵报告的地址都无法确认时(如ROLE没有权限)，使用第一个哨兵报告的地址
+func getMasterBySentinels(masterName string, sentinels []HostAndPort, builder PoolBuilder) HostAndPort {
+	var reported HostAndPort
 	for _, sentinel := range sentinels {
 		gedis, _ := NewGedis(sentinel.GetHost(), sentinel.GetPort())
 		if gedis != nil {
-			reply, sErr := gedis.Sentinel(SENTINEL_GET_MASTER_ADDR_BY_NAME, masterName)
-			if reply != nil && len(reply) == 2 && sErr == nil {
-				master = HostAndPort{
-					host:reply[0],
-					port: int(reply[1]),
+			addr, sErr := gedis.SentinelGetMasterAddrByName(masterName)
+			gedis.Close()
+			if sErr == nil {
+				if isMaster(addr, builder) {
+					return addr
+				}
+				if reported == (HostAndPort{}) {
+					reported = addr
 				}
-				gedis.Close()
-				break
 			}
-			gedis.Close()
 		}
 	}
-	return master
+	return reported
 }
 
 func (sgp *SentinelGedisPool)initSentinels(masterName string, sentinels []HostAndPort) error {
-	for i, sentinel := range sentinels {
+	for _, sentinel := range sentinels {
 		// 创建到Sentinel的连接对象
-		g, err := sgp.builder(sentinel.GetHost(), sentinel.GetPort())// NewGedis(sentinel.GetHost(), sentinel.GetPort())
+		g, err := NewGedis(sentinel.GetHost(), sentinel.GetPort())
 		if err != nil {
-			return errors.New("cannt connect to sentinel(" + sentinel.Str() + "):" + err)
+			return errors.New("cannt connect to sentinel(" + sentinel.Str() + "):" + err.Error())
 		}
 		// 创建一个订阅客户端
 		subClient := NewPubSubClient(g)
 		// 订阅 master切换channel
 		r := subClient.Subscribe(SENTINEL_SUBSCRIBE_CHANNEL)
 		if r.Err != nil {
-			return errors.New("subsribe switch master error:" + err)
+			return errors.New("subsribe switch master error:" + r.Err.Error())
 		}
 		listener := &SentinelListener{
 			pool: sgp,
@@ -125,29 +159,40 @@ func (sgp *SentinelGedisPool)initSentinels(masterName string, sentinels []HostAn
 			switchMasterChannel: make(chan *switchMaster),
 		}
 		listener.start()
-		sgp.sentinelListeners[i] = listener
+		sgp.sentinelListeners = append(sgp.sentinelListeners, listener)
 	}
 	return nil
 }
 
-func (sgp *SentinelGedisPool) initPool(master HostAndPort) error {
-	gs := make([]*Gedis, 0, sgp.size)
-	for i := 0; i < sgp.size; i++ {
-		gedis, err := sgp.builder(master.GetHost(), master.GetPort())
-		if err != nil {
-			for _, g := range gs {
-				g.Close()
-			}
-			return errors.New("cannt connect to master(" + master.Str() + "),error:" + err)
-		}
-		if gedis != nil {
-			gs = append(gs, gedis)
-		}
+// 创建到master的连接池，会预先建立MinIdle个连接，调用时不要持有sgp.mutex
+func (sgp *SentinelGedisPool)newMasterPool(master HostAndPort) (*boundedPool[*Gedis], error) {
+	pool, err := newBoundedPool(sgp.config, func() (*Gedis, error) {
+		return sgp.builder(master.GetHost(), master.GetPort())
+	}, closeGedis, pingGedis)
+	if err != nil {
+		return nil, errors.New("cannt connect to master(" + master.Str() + "),error:" + err.Error())
 	}
-	sgp.pool = make(chan *Gedis, len(gs))
-	for i := range gs {
-		sgp.pool <- gs[i]
+	return pool, nil
+}
+
+// 切换到新master的连接池：在锁外建立连接，只在替换时加锁，并关闭旧的连接池
+func (sgp *SentinelGedisPool)switchMaster(master HostAndPort) error {
+	pool, err := sgp.newMasterPool(master)
+	if err != nil {
+		return err
+	}
+	sgp.mutex.Lock()
+	defer sgp.mutex.Unlock()
+	select {
+	case <-sgp.pool.closeChannel:
+		// 建立连接期间SentinelGedisPool已被关闭
+		pool.close()
+		return ErrPoolClosed
+	default:
 	}
+	sgp.pool.close()
+	sgp.pool = pool
+	sgp.currentHostMaster = master
 	return nil
 }
 
@@ -178,7 +223,12 @@ func (l *SentinelListener)start() {
 			sMsg := strings.Split(r.Message, " ")
 			name := sMsg[0]
 			if name == l.masterName {
-				newAddr := HostAndPort{sMsg[3], sMsg[4]}
+				// <master-name> <old-ip> <old-port> <new-ip> <new-port>
+				port, err := strconv.Atoi(sMsg[4])
+				if err != nil {
+					continue
+				}
+				newAddr := HostAndPort{sMsg[3], port}
 				select {
 				case l.switchMasterChannel <- &switchMaster{name, newAddr}:
 				case <-l.closeChannel:
@@ -193,9 +243,8 @@ func (l *SentinelListener)start() {
 		for l.running {
 			select {
 			case sm := <-l.switchMasterChannel:
-				l.pool.mutex.Lock()
-				l.pool.initPool(sm.addr, l.pool.size)
-				l.pool.mutex.Unlock()
+				// TODO logging
+				l.pool.switchMaster(sm.addr)
 			case <-l.closeChannel:
 				l.pool.Close()
 				return
@@ -0,0 +1,69 @@
+package gedis
+
+import (
+	"testing"
+)
+
+// 不连接哨兵，直接创建到master的SentinelGedisPool
+func newTestSentinelPool(t *testing.T, master HostAndPort) *SentinelGedisPool {
+	t.Helper()
+	sgp := &SentinelGedisPool{
+		config:            PoolConfig{MaxActive: 2, MinIdle: 1},
+		currentHostMaster: master,
+		builder: func(host string, port int) (*Gedis, error) {
+			g, _ := newFakeGedis()
+			return g, nil
+		},
+	}
+	pool, err := sgp.newMasterPool(master)
+	if err != nil {
+		t.Fatalf("newMasterPool: %v", err)
+	}
+	sgp.pool = pool
+	return sgp
+}
+
+func TestSentinelPoolSwitchMaster(t *testing.T) {
+	sgp := newTestSentinelPool(t, HostAndPort{"10.0.0.1", 6379})
+	defer sgp.Close()
+	before, err := sgp.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	old := sgp.pool
+
+	newMaster := HostAndPort{"10.0.0.2", 6379}
+	if err := sgp.switchMaster(newMaster); err != nil {
+		t.Fatalf("switchMaster: %v", err)
+	}
+	if sgp.pool == old || sgp.currentHostMaster != newMaster {
+		t.Fatalf("pool was not switched to %v", newMaster)
+	}
+	if _, err := old.get(); err != ErrPoolClosed {
+		t.Errorf("old pool get err = %v, want ErrPoolClosed", err)
+	}
+
+	// 切换前取出的连接归还时被关闭，不会进入新的连接池
+	before.Close()
+	if sgp.pool.owns(before) || len(sgp.pool.idle) != 1 {
+		t.Errorf("connection from the old master was returned to the new pool")
+	}
+	after, err := sgp.Get()
+	if err != nil {
+		t.Fatalf("Get after switch: %v", err)
+	}
+	if !sgp.pool.owns(after) {
+		t.Error("Get did not use the new pool")
+	}
+}
+
+func TestSentinelPoolSwitchAfterClose(t *testing.T) {
+	sgp := newTestSentinelPool(t, HostAndPort{"10.0.0.1", 6379})
+	sgp.Close()
+	if err := sgp.switchMaster(HostAndPort{"10.0.0.2", 6379}); err != ErrPoolClosed {
+		t.Fatalf("switchMaster err = %v, want ErrPoolClosed", err)
+	}
+	if _, err := sgp.Get(); err != ErrPoolClosed {
+		t.Errorf("Get err = %v, want ErrPoolClosed", err)
+	}
+}
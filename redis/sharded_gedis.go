@@ -6,6 +6,7 @@ import (
 	"sort"
 	"sync"
 	"errors"
+	"time"
 )
 
 const (
@@ -34,23 +35,30 @@ func NewShardedGedis(shards []ShardInfo) (*ShardedGedis, error) {
 		for i, s := range shards {
 			g, err := NewGedis(s.host, s.port)
 			if err != nil {
-				if s.name == nil || s.name == "" {
-					for n := 0; n < Virtual_Node_Magic * s.Weight; n++ {
-						hashCode := hash("SHARD-" + strconv.Itoa(i) + "-NODE-" + strconv.Itoa(n))
-						nodes[hashCode] = s
-						hashCodes = append(hashCodes, hashCode)
-					}
-				}else {
-					for n := 0; n < 160 * s.Weight; n++ {
-						hashCode := hash(s.name + "*" + strconv.Itoa(s.Weight)) + strconv.Itoa(n)
-						nodes[hashCode] = s
-						hashCodes = append(hashCodes, hashCode)
-					}
+				for _, opened := range resources {
+					opened.Close()
 				}
-				resources[s] = g
+				return nil, err
 			}
+			if s.name == "" {
+				for n := 0; n < Virtual_Node_Magic * s.Weight; n++ {
+					hashCode := hash("SHARD-" + strconv.Itoa(i) + "-NODE-" + strconv.Itoa(n))
+					nodes[hashCode] = s
+					hashCodes = append(hashCodes, hashCode)
+				}
+			}else {
+				for n := 0; n < 160 * s.Weight; n++ {
+					hashCode := hash(s.name + "*" + strconv.Itoa(s.Weight) + strconv.Itoa(n))
+					nodes[hashCode] = s
+					hashCodes = append(hashCodes, hashCode)
+				}
+			}
+			resources[s] = g
 		}
 		sort.Sort(hashCodes)
+		if len(shards) > 0 {
+			DefaultCommands.loadOnce(resources[shards[0]])
+		}
 		sg := ShardedGedis{
 			Nodes: nodes,
 			HashCodes:hashCodes,
@@ -116,7 +124,7 @@ func (sk SortNumber) Less(i, j int) bool {
 	return sk[i] < sk[j]
 }
 
-​func (sk SortNumber) Swap(i, j int) {
+func (sk SortNumber) Swap(i, j int) {
 	sk[i], sk[j] = sk[j], sk[i]
 }
 
@@ -139,7 +147,7 @@ func NewShardInfoWithName(name, host string, port int) (*ShardInfo) {
 	return NewShardInfoWithNameAndWeight(name, host, port, Default_Weight)
 }
 
-func NewShardInfoWithWeight(host string, port, weight int) {
+func NewShardInfoWithWeight(host string, port, weight int) (*ShardInfo) {
 	return NewShardInfoWithNameAndWeight("", host, port, weight)
 }
 
@@ -171,6 +179,17 @@ func (s *ShardedGedis)GetGedis(key string) (*Gedis) {
 	return s.getShard(key)
 }
 
+// 返回所有分片的Gedis实例，用于需要在每个节点上执行的命令(如部署函数库、收集诊断信息等)
+func (s *ShardedGedis)AllShards() []*Gedis {
+	s.RLock()
+	defer s.RUnlock()
+	shards := make([]*Gedis, 0, len(s.resources))
+	for _, g := range s.resources {
+		shards = append(shards, g)
+	}
+	return shards
+}
+
 func (s *ShardedGedis)Get(key string) *Reply {
 	gedis := s.getShard(key)
 	return gedis.Get(key)
@@ -181,9 +200,402 @@ func (s *ShardedGedis)Set(key string, value interface{}) (string, error) {
 	return gedis.Set(key, value)
 }
 
-func (s *ShardedGedis)Del(key string) (int, error) {
-	gedis := s.getShard(key)
-	return gedis.Del(key)
+// 按分片分组后分别执行DEL，返回删除的总数
+func (s *ShardedGedis)Del(keys... string) (int, error) {
+	total := 0
+	for g, idx := range s.groupByShard(keys) {
+		shardKeys := make([]string, len(idx))
+		for i, n := range idx {
+			shardKeys[i] = keys[n]
+		}
+		n, err := g.Del(shardKeys...)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (s *ShardedGedis)SetWithOptions(key string, value interface{}, opt *SetOptions) (string, error) {
+	return s.getShard(key).SetWithOptions(key, value, opt)
+}
+
+func (s *ShardedGedis)GetEx(key string, opt *GetExOptions) (string, error) {
+	return s.getShard(key).GetEx(key, opt)
+}
+
+func (s *ShardedGedis)GetDel(key string) (string, error) {
+	return s.getShard(key).GetDel(key)
+}
+
+func (s *ShardedGedis)GetSet(key string, value interface{}) (string, error) {
+	return s.getShard(key).GetSet(key, value)
+}
+
+func (s *ShardedGedis)Incr(key string) (int64, error) {
+	return s.getShard(key).Incr(key)
+}
+
+func (s *ShardedGedis)IncrBy(key string, increment int64) (int64, error) {
+	return s.getShard(key).IncrBy(key, increment)
+}
+
+func (s *ShardedGedis)IncrByFloat(key string, increment float64) (float64, error) {
+	return s.getShard(key).IncrByFloat(key, increment)
+}
+
+func (s *ShardedGedis)Decr(key string) (int64, error) {
+	return s.getShard(key).Decr(key)
+}
+
+func (s *ShardedGedis)DecrBy(key string, decrement int64) (int64, error) {
+	return s.getShard(key).DecrBy(key, decrement)
+}
+
+// 按分片分组后分别执行MGET，再按keys的顺序组装结果
+func (s *ShardedGedis)MGet(keys... string) ([][]byte, error) {
+	values := make([][]byte, len(keys))
+	for g, idx := range s.groupByShard(keys) {
+		shardKeys := make([]string, len(idx))
+		for i, n := range idx {
+			shardKeys[i] = keys[n]
+		}
+		vs, err := g.MGet(shardKeys...)
+		if err != nil {
+			return nil, err
+		}
+		for i, n := range idx {
+			values[n] = vs[i]
+		}
+	}
+	return values, nil
+}
+
+// 按分片分组后分别执行MSET，注意不同分片之间不是原子的
+func (s *ShardedGedis)MSet(pairs map[string]interface{}) (string, error) {
+	shards := make(map[*Gedis]map[string]interface{})
+	for k, v := range pairs {
+		g := s.getShard(k)
+		if shards[g] == nil {
+			shards[g] = make(map[string]interface{})
+		}
+		shards[g][k] = v
+	}
+	var status string
+	for g, p := range shards {
+		var err error
+		if status, err = g.MSet(p); err != nil {
+			return "", err
+		}
+	}
+	return status, nil
+}
+
+// MSETNX需要保证原子性，所以只支持所有key都落在同一个分片上的情况
+func (s *ShardedGedis)MSetNX(pairs map[string]interface{}) (bool, error) {
+	keys := make([]string, 0, len(pairs))
+	for k := range pairs {
+		keys = append(keys, k)
+	}
+	g, err := s.sameShard(keys...)
+	if err != nil {
+		return false, err
+	}
+	return g.MSetNX(pairs)
+}
+
+func (s *ShardedGedis)Append(key string, value interface{}) (int64, error) {
+	return s.getShard(key).Append(key, value)
+}
+
+func (s *ShardedGedis)GetRange(key string, start, end int64) (string, error) {
+	return s.getShard(key).GetRange(key, start, end)
+}
+
+func (s *ShardedGedis)SetRange(key string, offset int64, value interface{}) (int64, error) {
+	return s.getShard(key).SetRange(key, offset, value)
+}
+
+func (s *ShardedGedis)StrLen(key string) (int64, error) {
+	return s.getShard(key).StrLen(key)
+}
+
+func (s *ShardedGedis)LCS(key1, key2 string) (string, error) {
+	g, err := s.sameShard(key1, key2)
+	if err != nil {
+		return "", err
+	}
+	return g.LCS(key1, key2)
+}
+
+func (s *ShardedGedis)LCSLen(key1, key2 string) (int64, error) {
+	g, err := s.sameShard(key1, key2)
+	if err != nil {
+		return 0, err
+	}
+	return g.LCSLen(key1, key2)
+}
+
+func (s *ShardedGedis)LCSIdx(key1, key2 string, minMatchLen int64, withMatchLen bool) (*LCSMatch, error) {
+	g, err := s.sameShard(key1, key2)
+	if err != nil {
+		return nil, err
+	}
+	return g.LCSIdx(key1, key2, minMatchLen, withMatchLen)
+}
+
+// 按分片分组后分别执行EXISTS，返回存在的总数
+func (s *ShardedGedis)Exists(keys... string) (int64, error) {
+	var total int64
+	for g, idx := range s.groupByShard(keys) {
+		shardKeys := make([]string, len(idx))
+		for i, n := range idx {
+			shardKeys[i] = keys[n]
+		}
+		n, err := g.Exists(shardKeys...)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+func (s *ShardedGedis)Type(key string) (string, error) {
+	return s.getShard(key).Type(key)
+}
+
+func (s *ShardedGedis)Expire(key string, ttl time.Duration, cond ExpireCondition) (bool, error) {
+	return s.getShard(key).Expire(key, ttl, cond)
+}
+
+func (s *ShardedGedis)PExpire(key string, ttl time.Duration, cond ExpireCondition) (bool, error) {
+	return s.getShard(key).PExpire(key, ttl, cond)
+}
+
+func (s *ShardedGedis)TTL(key string) (KeyTTL, error) {
+	return s.getShard(key).TTL(key)
+}
+
+func (s *ShardedGedis)Persist(key string) (bool, error) {
+	return s.getShard(key).Persist(key)
+}
+
+func (s *ShardedGedis)HSet(key string, pairs map[string]interface{}) (int64, error) {
+	return s.getShard(key).HSet(key, pairs)
+}
+
+func (s *ShardedGedis)HGet(key, field string) (string, error) {
+	return s.getShard(key).HGet(key, field)
+}
+
+func (s *ShardedGedis)HMGet(key string, fields... string) ([][]byte, error) {
+	return s.getShard(key).HMGet(key, fields...)
+}
+
+func (s *ShardedGedis)HGetAll(key string) (map[string]string, error) {
+	return s.getShard(key).HGetAll(key)
+}
+
+func (s *ShardedGedis)HDel(key string, fields... string) (int64, error) {
+	return s.getShard(key).HDel(key, fields...)
+}
+
+func (s *ShardedGedis)HExists(key, field string) (bool, error) {
+	return s.getShard(key).HExists(key, field)
+}
+
+func (s *ShardedGedis)HIncrBy(key, field string, increment int64) (int64, error) {
+	return s.getShard(key).HIncrBy(key, field, increment)
+}
+
+func (s *ShardedGedis)HIncrByFloat(key, field string, increment float64) (float64, error) {
+	return s.getShard(key).HIncrByFloat(key, field, increment)
+}
+
+func (s *ShardedGedis)HKeys(key string) ([]string, error) {
+	return s.getShard(key).HKeys(key)
+}
+
+func (s *ShardedGedis)HVals(key string) ([]string, error) {
+	return s.getShard(key).HVals(key)
+}
+
+func (s *ShardedGedis)HLen(key string) (int64, error) {
+	return s.getShard(key).HLen(key)
+}
+
+func (s *ShardedGedis)HSetNX(key, field string, value interface{}) (bool, error) {
+	return s.getShard(key).HSetNX(key, field, value)
+}
+
+func (s *ShardedGedis)LPush(key string, values... interface{}) (int64, error) {
+	return s.getShard(key).LPush(key, values...)
+}
+
+func (s *ShardedGedis)RPush(key string, values... interface{}) (int64, error) {
+	return s.getShard(key).RPush(key, values...)
+}
+
+func (s *ShardedGedis)LPop(key string) (string, error) {
+	return s.getShard(key).LPop(key)
+}
+
+func (s *ShardedGedis)RPop(key string) (string, error) {
+	return s.getShard(key).RPop(key)
+}
+
+func (s *ShardedGedis)LLen(key string) (int64, error) {
+	return s.getShard(key).LLen(key)
+}
+
+func (s *ShardedGedis)LRange(key string, start, stop int64) ([]string, error) {
+	return s.getShard(key).LRange(key, start, stop)
+}
+
+func (s *ShardedGedis)LIndex(key string, index int64) (string, error) {
+	return s.getShard(key).LIndex(key, index)
+}
+
+func (s *ShardedGedis)LSet(key string, index int64, value interface{}) (string, error) {
+	return s.getShard(key).LSet(key, index, value)
+}
+
+func (s *ShardedGedis)LRem(key string, count int64, value interface{}) (int64, error) {
+	return s.getShard(key).LRem(key, count, value)
+}
+
+func (s *ShardedGedis)LTrim(key string, start, stop int64) (string, error) {
+	return s.getShard(key).LTrim(key, start, stop)
+}
+
+func (s *ShardedGedis)SAdd(key string, members... interface{}) (int64, error) {
+	return s.getShard(key).SAdd(key, members...)
+}
+
+func (s *ShardedGedis)SRem(key string, members... interface{}) (int64, error) {
+	return s.getShard(key).SRem(key, members...)
+}
+
+func (s *ShardedGedis)SMembers(key string) ([]string, error) {
+	return s.getShard(key).SMembers(key)
+}
+
+func (s *ShardedGedis)SIsMember(key string, member interface{}) (bool, error) {
+	return s.getShard(key).SIsMember(key, member)
+}
+
+func (s *ShardedGedis)SCard(key string) (int64, error) {
+	return s.getShard(key).SCard(key)
+}
+
+func (s *ShardedGedis)SPop(key string) (string, error) {
+	return s.getShard(key).SPop(key)
+}
+
+func (s *ShardedGedis)SRandMember(key string) (string, error) {
+	return s.getShard(key).SRandMember(key)
+}
+
+func (s *ShardedGedis)ZAdd(key string, opt *ZAddOptions, members... Z) (int64, error) {
+	return s.getShard(key).ZAdd(key, opt, members...)
+}
+
+func (s *ShardedGedis)ZRem(key string, members... interface{}) (int64, error) {
+	return s.getShard(key).ZRem(key, members...)
+}
+
+func (s *ShardedGedis)ZScore(key string, member interface{}) (float64, error) {
+	return s.getShard(key).ZScore(key, member)
+}
+
+func (s *ShardedGedis)ZIncrBy(key string, increment float64, member interface{}) (float64, error) {
+	return s.getShard(key).ZIncrBy(key, increment, member)
+}
+
+func (s *ShardedGedis)ZCard(key string) (int64, error) {
+	return s.getShard(key).ZCard(key)
+}
+
+func (s *ShardedGedis)ZCount(key string, min, max ScoreBound) (int64, error) {
+	return s.getShard(key).ZCount(key, min, max)
+}
+
+func (s *ShardedGedis)ZRank(key string, member interface{}) (int64, error) {
+	return s.getShard(key).ZRank(key, member)
+}
+
+func (s *ShardedGedis)ZRevRank(key string, member interface{}) (int64, error) {
+	return s.getShard(key).ZRevRank(key, member)
+}
+
+func (s *ShardedGedis)ZRange(z *ZRangeArgs) ([]string, error) {
+	return s.getShard(z.Key).ZRange(z)
+}
+
+// 执行任意命令：通过DefaultCommands找出命令中的key并路由到key所在的分片，多个key必须在同一个分片上；
+// 元数据无法确定key时(如未知命令)通过COMMAND GETKEYS由服务端解析
+func (s *ShardedGedis)Cmd(cmd string, args... interface{}) *Reply {
+	g, err := s.routeCmd(cmd, args)
+	if err != nil {
+		return &Reply{Type: ErrorReply, Err: err}
+	}
+	return g.Cmd(cmd, args...)
+}
+
+func (s *ShardedGedis)routeCmd(cmd string, args []interface{}) (*Gedis, error) {
+	argv, err := commandArgv(cmd, args)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	ok := false
+	if info := DefaultCommands.Lookup(argv); info != nil {
+		keys, ok = info.Keys(argv)
+	}
+	if !ok {
+		shards := s.AllShards()
+		if len(shards) == 0 {
+			return nil, errors.New("no shard available")
+		}
+		if keys, err = shards[0].CommandGetKeys(cmd, args...); err != nil {
+			return nil, err
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("cannot route command without keys: " + cmd)
+	}
+	return s.sameShard(keys...)
 }
 
 // TODO 其它API待补充
+
+// 当多个key不在同一个分片上时，多key命令无法执行
+var CrossShardError = errors.New("keys are not in the same shard")
+
+// 将keys按所在分片分组，value为key在keys中的下标
+func (s *ShardedGedis)groupByShard(keys []string) map[*Gedis][]int {
+	groups := make(map[*Gedis][]int)
+	for i, k := range keys {
+		g := s.getShard(k)
+		groups[g] = append(groups[g], i)
+	}
+	return groups
+}
+
+// 返回keys共同所在的分片，不在同一分片时返回CrossShardError
+func (s *ShardedGedis)sameShard(keys... string) (*Gedis, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no key specified")
+	}
+	var g *Gedis
+	for _, k := range keys {
+		shard := s.getShard(k)
+		if g != nil && shard != g {
+			return nil, CrossShardError
+		}
+		g = shard
+	}
+	return g, nil
+}
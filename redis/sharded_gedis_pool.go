@@ -3,69 +3,84 @@ package gedis
 type ShardedGedisPool struct {
 	shards  []ShardInfo
 
-	pool    chan *ShardedGedis
+	pool    *boundedPool[*ShardedGedis]
 
 	builder ShardedPoolBuilder
 }
 
 type ShardedPoolBuilder func(shards []ShardInfo) (*ShardedGedis, error)
 
-// 默认使用
+// 默认使用NewShardedGedis，最多size个连接，参见DefaultPoolConfig
 func NewShardedPool(shards []ShardInfo, size int) (*ShardedGedisPool, error) {
-	return NewShardedPoolWithCustom(shards, size, NewShardedGedis(shards))
+	return NewShardedPoolWithCustom(shards, size, NewShardedGedis)
 }
 
 func NewShardedPoolWithCustom(shards []ShardInfo, size int, builder ShardedPoolBuilder) (*ShardedGedisPool, error) {
-	gs := make([]*ShardedGedis, 0, size)
-	for i := 0; i < size; i++ {
-		g, err := builder(shards)
-		if err != nil {
-			for _, g := range gs {
-				g.Close()
-			}
-			return nil, err
-		}
-		if g != nil {
-			gs = append(gs, g)
-		}
+	return NewShardedPoolWithConfig(shards, DefaultPoolConfig(size), builder)
+}
+
+// builder为nil时使用NewShardedGedis；PING检查在每一个分片上执行
+func NewShardedPoolWithConfig(shards []ShardInfo, config PoolConfig, builder ShardedPoolBuilder) (*ShardedGedisPool, error) {
+	if builder == nil {
+		builder = NewShardedGedis
 	}
-	p := ShardedGedisPool{
+	p := &ShardedGedisPool{
 		shards: shards,
-		pool: make(chan *ShardedGedis, len(gs)),
 		builder: builder,
 	}
-	for i := range gs {
-		p.pool <- gs[i]
+	pool, err := newBoundedPool(config, func() (*ShardedGedis, error) {
+		sg, err := builder(shards)
+		if err != nil {
+			return nil, err
+		}
+		// 自定义的builder可能没有经过NewShardedGedis，在这里也尝试加载命令元数据
+		if all := sg.AllShards(); len(all) > 0 {
+			DefaultCommands.loadOnce(all[0])
+		}
+		return sg, nil
+	}, closeShardedGedis, pingShardedGedis)
+	if err != nil {
+		return nil, err
 	}
-	return &p, nil
+	p.pool = pool
+	return p, nil
 }
 
 func (p *ShardedGedisPool)Get() (*ShardedGedis, error) {
-	select {
-	case sg := <-p.pool:
-		sg.Pool = p
-		return sg, nil
-	default:
-		return p.builder(p.shards), nil
+	sg, err := p.pool.get()
+	if err != nil {
+		return nil, err
 	}
+	sg.Pool = p
+	return sg, nil
 }
 
 func (sgp *ShardedGedisPool)Put(sg *ShardedGedis) {
-	select {
-	case sgp <- sg:
-	default:
-		sg.Close()
-	}
+	sgp.pool.put(sg)
 }
 
 func (sgp *ShardedGedisPool)Empty() {
-	var g ShardedGedis
-	for {
-		select {
-		case g <- sgp.pool:
-			g.Close()
-		default:
-			return
+	sgp.pool.empty()
+}
+
+// 停止后台检查并关闭所有空闲的连接
+func (sgp *ShardedGedisPool)Close() {
+	sgp.pool.close()
+}
+
+func pingShardedGedis(sg *ShardedGedis) error {
+	for _, g := range sg.AllShards() {
+		if err := pingGedis(g); err != nil {
+			return err
 		}
 	}
-}
\ No newline at end of file
+	return nil
+}
+
+// 真正关闭每个分片的连接而不是还回连接池，与closeGedis一样不发送QUIT
+func closeShardedGedis(sg *ShardedGedis) {
+	sg.Pool = nil
+	for _, g := range sg.AllShards() {
+		closeGedis(g)
+	}
+}